@@ -6,6 +6,7 @@ package main
 import (
 	"bufio"
 	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"os"
@@ -209,6 +210,10 @@ func read_input(filename string) (elves List) {
 
 func main() {
 
+	size := flag.Int("k", 0, "number of Elves to redistribute the items to (0 keeps the current number)")
+	method := flag.String("method", "auto", "redistribution solver: exact, lpt, kk or auto")
+	flag.Parse()
+
 	const filename string = "input.txt"
 	elves := read_input(filename)
 	elves.print()
@@ -227,5 +232,13 @@ func main() {
 		k += 1
 	}
 	fmt.Printf("The top three Elves carry a total of %d Calories.\n", total)
+	fmt.Println("********************************")
+
+	plan, err := elves.Redistribute(*size, *method)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	plan.Print()
 	return
 }
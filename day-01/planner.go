@@ -0,0 +1,295 @@
+// Miguel Nobre Castro
+// https://adventofcode.com/2022/day/1
+
+package main
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// Maximum number of items solved exactly by the planner.
+const EXACT_MAX int = 24
+
+// Maximum number of assignments explored by the exact solver.
+const EXACT_NODES int = 2000000
+
+// Plan struct of the items redistributed among 'k' Elves.
+type Plan struct {
+	k         int    // Number of Elves
+	method    string // Solver used ("exact", "lpt" or "kk")
+	elves     List   // Elves carrying the reassigned items
+	max       int    // Largest sum of cals carried by an Elf
+	min       int    // Smallest sum of cals carried by an Elf
+	imbalance int    // Difference between 'max' and 'min'
+}
+
+// Collects the items carried by all the Elves in the list.
+func (l *List) Items() (items []int) {
+
+	items = make([]int, 0)
+	ptr := l.head
+	for ptr != nil {
+		items = append(items, ptr.items...)
+		ptr = ptr.next
+	}
+	return
+}
+
+// Redistributes the items of the Elves among 'k' Elves to minimize the
+// maximum cals carried. The 'method' is one of "exact", "lpt", "kk" or
+// "auto", which solves exactly up to EXACT_MAX items and uses "kk" otherwise,
+// or when the exact solver explores more than EXACT_NODES assignments.
+func (l *List) Redistribute(k int, method string) (plan *Plan, err error) {
+
+	if k <= 0 {
+		k = l.num
+	}
+	if k <= 0 {
+		return nil, errors.New("Planner: no Elves to carry the items.")
+	}
+	items := l.Items()
+	auto := method == "auto"
+	if auto {
+		if len(items) <= EXACT_MAX {
+			method = "exact"
+		} else {
+			method = "kk"
+		}
+	}
+
+	var bins [][]int
+	switch method {
+	case "exact":
+		if len(items) > EXACT_MAX {
+			return nil, fmt.Errorf("Planner: %d items exceed the exact solver limit of %d.", len(items), EXACT_MAX)
+		}
+		var solved bool
+		bins, solved = balanceExact(items, k)
+		if !solved && auto {
+			method = "kk"
+			bins = balanceKK(items, k)
+		} else if !solved {
+			return nil, fmt.Errorf("Planner: the exact solver explored more than %d assignments.", EXACT_NODES)
+		}
+	case "lpt":
+		bins = balanceLPT(items, k)
+	case "kk":
+		bins = balanceKK(items, k)
+	default:
+		return nil, fmt.Errorf("Planner: unknown method '%s'.", method)
+	}
+
+	plan = &Plan{
+		k:      k,
+		method: method,
+	}
+	for idx, bin := range bins {
+		plan.elves.append(idx, bin)
+	}
+	plan.max, plan.min = loads(bins)
+	plan.imbalance = plan.max - plan.min
+	return
+}
+
+// Prints the Plan and the resulting assignment.
+func (plan *Plan) Print() {
+
+	fmt.Printf("Plan (%s) redistributes the items among %d Elves:\n", plan.method, plan.k)
+	ptr := plan.elves.head
+	for ptr != nil {
+		fmt.Printf("Elf no.%d carries %d items (%d cals) %v.\n", ptr.idx, ptr.n, ptr.sum, ptr.items)
+		ptr = ptr.next
+	}
+	if plan.elves.num < plan.k {
+		fmt.Printf("%d Elves are left without items.\n", plan.k-plan.elves.num)
+	}
+	fmt.Printf("Max %d cals, min %d cals, imbalance of %d cals.\n", plan.max, plan.min, plan.imbalance)
+	return
+}
+
+// Largest and smallest sums of cals among the bins.
+func loads(bins [][]int) (max int, min int) {
+
+	for i, bin := range bins {
+		sum := 0
+		for _, item := range bin {
+			sum += item
+		}
+		if i == 0 || sum > max {
+			max = sum
+		}
+		if i == 0 || sum < min {
+			min = sum
+		}
+	}
+	return
+}
+
+// Items sorted by decreasing cals.
+func sortDesc(items []int) (sorted []int) {
+
+	sorted = append([]int(nil), items...)
+	sort.Sort(sort.Reverse(sort.IntSlice(sorted)))
+	return
+}
+
+// Longest Processing Time: each item goes to the least loaded bin.
+func balanceLPT(items []int, k int) (bins [][]int) {
+
+	bins = make([][]int, k)
+	sums := make([]int, k)
+	for _, item := range sortDesc(items) {
+		j := 0
+		for i := range sums {
+			if sums[i] < sums[j] {
+				j = i
+			}
+		}
+		bins[j] = append(bins[j], item)
+		sums[j] += item
+	}
+	return
+}
+
+// Branch and bound over all assignments, seeded with the LPT solution. It
+// gives up after EXACT_NODES assignments, returning the best one found and
+// false.
+func balanceExact(items []int, k int) (bins [][]int, solved bool) {
+
+	sorted := sortDesc(items)
+	best := balanceLPT(sorted, k)
+	bestMax, _ := loads(best)
+
+	// Lower bound: the largest item or the average load
+	total := 0
+	for _, item := range sorted {
+		total += item
+	}
+	bound := (total + k - 1) / k
+	if len(sorted) > 0 && sorted[0] > bound {
+		bound = sorted[0]
+	}
+
+	sums := make([]int, k)
+	assign := make([]int, len(sorted))
+	nodes := 0
+	var search func(i int, max int)
+	search = func(i int, max int) {
+		if max >= bestMax || bestMax == bound || nodes > EXACT_NODES {
+			return
+		}
+		nodes += 1
+		if i == len(sorted) {
+			bestMax = max
+			best = make([][]int, k)
+			for j, b := range assign {
+				best[b] = append(best[b], sorted[j])
+			}
+			return
+		}
+		for b := 0; b < k; b++ {
+			// Bins with the same load are interchangeable
+			dup := false
+			for c := 0; c < b && !dup; c++ {
+				dup = sums[c] == sums[b]
+			}
+			if dup {
+				continue
+			}
+			sums[b] += sorted[i]
+			assign[i] = b
+			next := max
+			if sums[b] > next {
+				next = sums[b]
+			}
+			search(i+1, next)
+			sums[b] -= sorted[i]
+		}
+	}
+	search(0, 0)
+	bins = best
+	solved = nodes <= EXACT_NODES
+	return
+}
+
+// Partial partition of the items used by the Karmarkar-Karp differencing.
+type subsets struct {
+	sums []int   // Sums of the subsets in decreasing order
+	sets [][]int // Items of each subset
+}
+
+// Spread between the largest and the smallest subset.
+func (p *subsets) spread() int {
+
+	return p.sums[0] - p.sums[len(p.sums)-1]
+}
+
+// Max-heap of partitions ordered by their spread.
+type subsetsHeap []*subsets
+
+func (h subsetsHeap) Len() int           { return len(h) }
+func (h subsetsHeap) Less(i, j int) bool { return h[i].spread() > h[j].spread() }
+func (h subsetsHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *subsetsHeap) Push(x any) {
+
+	*h = append(*h, x.(*subsets))
+}
+
+func (h *subsetsHeap) Pop() any {
+
+	old := *h
+	p := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return p
+}
+
+// Multi-way Karmarkar-Karp: merges the two partitions with the largest
+// spread, pairing the largest subsets of one with the smallest of the other.
+func balanceKK(items []int, k int) (bins [][]int) {
+
+	h := &subsetsHeap{}
+	for _, item := range items {
+		p := &subsets{
+			sums: make([]int, k),
+			sets: make([][]int, k),
+		}
+		p.sums[0] = item
+		p.sets[0] = []int{item}
+		heap.Push(h, p)
+	}
+	if h.Len() == 0 {
+		bins = make([][]int, k)
+		return
+	}
+	for h.Len() > 1 {
+		a := heap.Pop(h).(*subsets)
+		b := heap.Pop(h).(*subsets)
+		merged := &subsets{
+			sums: make([]int, k),
+			sets: make([][]int, k),
+		}
+		for i := 0; i < k; i++ {
+			merged.sums[i] = a.sums[i] + b.sums[k-1-i]
+			merged.sets[i] = append(append([]int(nil), a.sets[i]...), b.sets[k-1-i]...)
+		}
+		sort.Sort(bySum{merged})
+		heap.Push(h, merged)
+	}
+	bins = heap.Pop(h).(*subsets).sets
+	return
+}
+
+// Sorts the subsets of a partition by decreasing sum.
+type bySum struct{ p *subsets }
+
+func (s bySum) Len() int           { return len(s.p.sums) }
+func (s bySum) Less(i, j int) bool { return s.p.sums[i] > s.p.sums[j] }
+func (s bySum) Swap(i, j int) {
+
+	s.p.sums[i], s.p.sums[j] = s.p.sums[j], s.p.sums[i]
+	s.p.sets[i], s.p.sets[j] = s.p.sets[j], s.p.sets[i]
+}
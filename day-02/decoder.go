@@ -0,0 +1,139 @@
+// Miguel Nobre Castro
+// https://adventofcode.com/2022/day/2
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Interpretation of the Player2 letters of an encrypted strategy guide.
+type Interpretation struct {
+	rules    *Rules
+	outcomes bool  // Letters mean how the round ends instead of the sign to throw
+	perm     []int // Meaning of each Player2 letter
+	summary  Summary
+}
+
+// Describes the meaning of each Player2 letter.
+func (in *Interpretation) String() string {
+
+	var b strings.Builder
+	for i, r := range []rune(in.rules.Player2) {
+		if i > 0 {
+			b.WriteString(" ")
+		}
+		if in.outcomes {
+			fmt.Fprintf(&b, "%c=%s", r, outcomeName(in.perm[i], len(in.perm)))
+		} else {
+			fmt.Fprintf(&b, "%c=%s", r, in.rules.Signs[in.perm[i]])
+		}
+	}
+	return b.String()
+}
+
+// Name of the 'k'-th outcome letter among 'n' (see Rules.Respond).
+func outcomeName(k int, n int) string {
+
+	d := k - (n-1)/2
+	switch {
+	case d == 0:
+		return "draw"
+	case d == 1:
+		return "win"
+	case d == -1:
+		return "lose"
+	case d > 0:
+		return fmt.Sprintf("win%d", d)
+	}
+	return fmt.Sprintf("lose%d", -d)
+}
+
+// Calls 'f' with every permutation of 0..n-1 (Heap's algorithm).
+func permutations(n int, f func([]int)) {
+
+	perm := make([]int, n)
+	for i := range perm {
+		perm[i] = i
+	}
+	c := make([]int, n)
+	f(perm)
+	i := 0
+	for i < n {
+		if c[i] < i {
+			if i%2 == 0 {
+				perm[0], perm[i] = perm[i], perm[0]
+			} else {
+				perm[c[i]], perm[i] = perm[i], perm[c[i]]
+			}
+			f(perm)
+			c[i] += 1
+			i = 0
+		} else {
+			c[i] = 0
+			i += 1
+		}
+	}
+	return
+}
+
+// Decodes a strategy guide: scores every Interpretation of the Player2
+// letters, both as signs and as outcomes, and ranks them by Player2's score.
+func Decode(rules *Rules, lines []string) (ranking []*Interpretation) {
+
+	n := len(rules.Signs)
+	for _, outcomes := range []bool{false, true} {
+		permutations(n, func(p []int) {
+			in := &Interpretation{
+				rules:    rules,
+				outcomes: outcomes,
+				perm:     append([]int(nil), p...),
+			}
+			strategy := func(player1 rune, player2 rune) (sign1, sign2 int) {
+				sign1, k := rules.Strat1(player1, player2)
+				sign2 = -1
+				if sign1 < 0 || k < 0 {
+					return
+				}
+				if in.outcomes {
+					sign2 = rules.Respond(sign1, in.perm[k])
+				} else {
+					sign2 = in.perm[k]
+				}
+				return
+			}
+			_, in.summary = NewMatch(rules).Replay(lines, strategy)
+			ranking = append(ranking, in)
+		})
+	}
+	sort.SliceStable(ranking, func(a, b int) bool {
+		return ranking[a].summary.player2.score > ranking[b].summary.player2.score
+	})
+	return
+}
+
+// Prints the 'top' best Interpretations, or all of them if 'top' is 0.
+func PrintInterpretations(ranking []*Interpretation, top int) {
+
+	total := 0
+	for _, in := range ranking {
+		total += in.summary.player2.score
+	}
+	if len(ranking) > 0 {
+		fmt.Printf("%d interpretations, expected score of %.1f over all of them.\n", len(ranking), float64(total)/float64(len(ranking)))
+	}
+	for i, in := range ranking {
+		if top > 0 && i >= top {
+			break
+		}
+		kind := "signs"
+		if in.outcomes {
+			kind = "outcomes"
+		}
+		fmt.Printf("%3d. %-8s %-40s score %d (%dW/%dD/%dL)\n", i+1, kind, in.String(), in.summary.player2.score,
+			in.summary.player2.wins, in.summary.player2.draws, in.summary.player2.losses)
+	}
+	return
+}
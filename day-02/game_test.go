@@ -0,0 +1,29 @@
+// Miguel Nobre Castro
+// https://adventofcode.com/2022/day/2
+
+package main
+
+import "testing"
+
+func TestReplayDraw(t *testing.T) {
+
+	g := NewMatch(rps(t))
+	records, summary := g.Replay([]string{"A X"}, g.rules.Strat1)
+	if len(records) != 1 {
+		t.Fatalf("%d records, want 1", len(records))
+	}
+	rec := records[0]
+	if rec.outcome != Draw {
+		t.Errorf("Outcome %s, want %s", rec.outcome, Draw)
+	}
+	if rec.shape1+rec.points1 != 4 || rec.shape2+rec.points2 != 4 {
+		t.Errorf("Points %d+%d vs %d+%d, want 4 vs 4", rec.shape1, rec.points1, rec.shape2, rec.points2)
+	}
+	want := Tally{score: 4, draws: 1}
+	if summary.player1 != want || summary.player2 != want {
+		t.Errorf("Summary %+v vs %+v, want %+v for both", summary.player1, summary.player2, want)
+	}
+	if summary.Outcome() != Draw {
+		t.Errorf("Game outcome %s, want %s", summary.Outcome(), Draw)
+	}
+}
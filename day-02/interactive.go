@@ -0,0 +1,89 @@
+// Miguel Nobre Castro
+// https://adventofcode.com/2022/day/2
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Input letter of Player1 for a sign.
+func (rules *Rules) Letter1(sign int) rune {
+
+	return []rune(rules.Player1)[sign]
+}
+
+// Input letter of Player2 for a sign.
+func (rules *Rules) Letter2(sign int) rune {
+
+	return []rune(rules.Player2)[sign]
+}
+
+// Parses a sign typed by a human, either as its Player2 letter or its name.
+func (rules *Rules) Parse(s string) (sign int, ok bool) {
+
+	s = strings.TrimSpace(s)
+	if r := []rune(strings.ToUpper(s)); len(r) == 1 {
+		sign, ok = rules.letter2[r[0]]
+		if ok {
+			return
+		}
+	}
+	for i, name := range rules.Signs {
+		if strings.EqualFold(s, name) {
+			return i, true
+		}
+	}
+	return -1, false
+}
+
+// Plays an interactive Game where the human is Player2, typing a sign on 'in'
+// every round, against the 'opponent' Strategy as Player1. The session ends on
+// "q" or at the end of 'in'. Every round is written to 'record', if not nil,
+// as a strategy guide line ("A Y") that replays the session with Rules.Strat1.
+func (g *Game) Interactive(opponent Strategy, in io.Reader, out io.Writer, record io.Writer) (records []Record, summary Summary) {
+
+	opponent.Reset(g.rules)
+	history := make([]Turn, 0)
+	choices := make([]string, len(g.rules.Signs))
+	for i, sign := range g.rules.Signs {
+		choices[i] = fmt.Sprintf("%c=%s", g.rules.Letter2(i), sign)
+	}
+
+	r := bufio.NewReader(in)
+	for true {
+		// The opponent commits to its sign before the human types it
+		sign1 := opponent.Move(history)
+
+		sign2 := -1
+		for sign2 < 0 {
+			fmt.Fprintf(out, "Round %d vs %s, your sign (%s, q to quit): ", g.rounds+1, opponent.Name(), strings.Join(choices, " "))
+			s, err := r.ReadString('\n')
+			if strings.TrimSpace(s) == "q" || (err != nil && len(strings.TrimSpace(s)) == 0) {
+				fmt.Fprintln(out)
+				break
+			}
+			if sign, ok := g.rules.Parse(s); ok {
+				sign2 = sign
+			} else {
+				fmt.Fprintf(out, "Unknown sign '%s'.\n", strings.TrimSpace(s))
+			}
+		}
+		if sign2 < 0 {
+			break
+		}
+
+		rec := g.score(sign1, sign2)
+		records = append(records, rec)
+		summary.add(rec)
+		history = append(history, Turn{own: sign1, opp: sign2, outcome: rec.outcome})
+		if record != nil {
+			fmt.Fprintf(record, "%c %c\n", g.rules.Letter1(sign1), g.rules.Letter2(sign2))
+		}
+	}
+	summary.rounds = g.rounds
+	return
+}
@@ -6,22 +6,129 @@ package main
 import (
 	"bufio"
 	"errors"
+	"flag"
 	"fmt"
 	"io"
+	"net"
 	"os"
+	"strings"
 )
 
+// Outcome of a round from the point of view of a player.
+type Outcome int
+
+const (
+	Lose Outcome = iota
+	Draw
+	Win
+)
+
+func (o Outcome) String() string {
+
+	return [...]string{"Lose", "Draw", "Win"}[o]
+}
+
+// The same Outcome from the point of view of the opponent.
+func (o Outcome) Reverse() Outcome {
+
+	return Win - o
+}
+
+// Record of a round played in a Game.
+type Record struct {
+	round   int     // Round number, starting at 1
+	sign1   string  // Sign thrown by Player1
+	sign2   string  // Sign thrown by Player2
+	outcome Outcome // Outcome for Player1
+	shape1  int     // Shape points of Player1
+	shape2  int     // Shape points of Player2
+	points1 int     // Outcome points of Player1
+	points2 int     // Outcome points of Player2
+	total1  int     // Running score of Player1
+	total2  int     // Running score of Player2
+}
+
+// Tally of the rounds of a player.
+type Tally struct {
+	score  int
+	wins   int
+	draws  int
+	losses int
+}
+
+// Adds a round with the given Outcome and points to the Tally.
+func (t *Tally) add(o Outcome, points int) {
+
+	t.score += points
+	switch o {
+	case Win:
+		t.wins += 1
+	case Draw:
+		t.draws += 1
+	case Lose:
+		t.losses += 1
+	}
+	return
+}
+
+// Summary of a Game.
+type Summary struct {
+	rounds  int
+	player1 Tally
+	player2 Tally
+}
+
+// Adds a played round to the Summary.
+func (s *Summary) add(rec Record) {
+
+	s.player1.add(rec.outcome, rec.shape1+rec.points1)
+	s.player2.add(rec.outcome.Reverse(), rec.shape2+rec.points2)
+	return
+}
+
+// Outcome of the Game for Player1.
+func (s Summary) Outcome() Outcome {
+
+	if s.player1.score > s.player2.score {
+		return Win
+	} else if s.player1.score == s.player2.score {
+		return Draw
+	}
+	return Lose
+}
+
+// Prints the Summary of the Game.
+func (s Summary) Print() {
+
+	fmt.Printf("!!!GAME OVER!!!\nFinal scores after %d rounds:\n", s.rounds)
+	fmt.Printf("Player1 - %d vs %d - Player 2\n", s.player1.score, s.player2.score)
+	fmt.Printf("Player1 %dW/%dD/%dL vs %dW/%dD/%dL Player2\n",
+		s.player1.wins, s.player1.draws, s.player1.losses,
+		s.player2.wins, s.player2.draws, s.player2.losses)
+	switch s.Outcome() {
+	case Win:
+		fmt.Println("Player1 WINS!")
+	case Draw:
+		fmt.Println("DRAW! Play again!")
+	default:
+		fmt.Println("Player2 WINS!")
+	}
+	return
+}
+
 // Game of Rock-Paper-Scissors
 type Game struct {
-	game    chan string
-	rounds  int
-	player1 rune
-	player2 rune
-	score1  int
-	score2  int
+	game     chan string
+	rules    *Rules
+	rounds   int
+	player1  rune
+	player2  rune
+	score1   int
+	score2   int
+	observer func(Record)
 }
 
-// Initiate a NewGame given an input .txt file
+// Initiate a NewGame given an input .txt file and its Rules
 //
 // Input file example:
 // "
@@ -29,7 +136,7 @@ type Game struct {
 // B X
 // C Z
 // "
-func NewGame(filename string) (g *Game) {
+func NewGame(filename string, rules *Rules) (g *Game) {
 
 	game := make(chan string, 1)
 	buffer := ""
@@ -61,6 +168,7 @@ func NewGame(filename string) (g *Game) {
 
 	g = &Game{
 		game:    game,
+		rules:   rules,
 		rounds:  0,
 		player1: '_',
 		player2: '_',
@@ -70,165 +178,277 @@ func NewGame(filename string) (g *Game) {
 	return
 }
 
-// Play a Game of Rock-Paper-Scissors
-func (g *Game) Play(strategy func(rune, rune) (int, int)) {
+// Initiate a new Game played by Strategies instead of an input file.
+func NewMatch(rules *Rules) (g *Game) {
 
-	for s := range g.game {
-		signs := []rune(s)
-		g.player1 = signs[0]
-		g.player2 = signs[2]
-
-		p1, p2 := strategy(g.player1, g.player2)
-
-		if Abs(p1) >= p2 {
-			fmt.Println("Player1 takes the round...")
-		} else if Abs(p1) == p2 {
-			fmt.Println("It's a draw...")
-		} else {
-			fmt.Println("Player2 takes the round...")
-		}
+	g = &Game{
+		rules:   rules,
+		rounds:  0,
+		player1: '_',
+		player2: '_',
+		score1:  0,
+		score2:  0,
+	}
+	return
+}
+
+// Sets an observer called with the Record of every round played.
+func (g *Game) Observe(observer func(Record)) {
+
+	g.observer = observer
+	return
+}
+
+// Scores a round given the signs of both players and records it.
+func (g *Game) score(sign1 int, sign2 int) (rec Record) {
 
-		g.score1 += p1
-		g.score2 += p2
-		g.rounds += 1
+	outcome := g.rules.Outcome(sign1, sign2)
+	g.rounds += 1
+	rec = Record{
+		round:   g.rounds,
+		sign1:   g.rules.Signs[sign1],
+		sign2:   g.rules.Signs[sign2],
+		outcome: outcome,
+		shape1:  g.rules.scores[sign1],
+		shape2:  g.rules.scores[sign2],
+		points1: g.rules.Points(outcome),
+		points2: g.rules.Points(outcome.Reverse()),
 	}
-	fmt.Printf("!!!GAME OVER!!!\nFinal scores after %d rounds:\n", g.rounds)
-	fmt.Printf("Player1 - %d vs %d - Player 2\n", Abs(g.score1), g.score2)
-	if Abs(g.score1) >= g.score2 {
-		fmt.Println("Player1 WINS!")
-	} else if Abs(g.score1) == g.score2 {
-		fmt.Println("DRAW! Play again!")
-	} else {
-		fmt.Println("Player2 WINS!")
+	g.score1 += rec.shape1 + rec.points1
+	g.score2 += rec.shape2 + rec.points2
+	rec.total1 = g.score1
+	rec.total2 = g.score2
+	if g.observer != nil {
+		g.observer(rec)
 	}
 	return
 }
 
-// Hand sign mapping
-func pts(r rune) (p int) {
+// Play a Game of Rock-Paper-Scissors given a strategy guide decoding the
+// letters of each line into the signs of both players.
+func (g *Game) Play(strategy func(rune, rune) (int, int)) (records []Record, summary Summary) {
 
-	keys := map[rune]int{
-		'A': -1, // Player1 Rock
-		'B': -2, // Player1 Paper
-		'C': -3, // Player1 Scissors
-		'X': 1,  // Player2 Rock
-		'Y': 2,  // Player2 Paper
-		'Z': 3,  // Player2 Scissors
+	for s := range g.game {
+		if rec, ok := g.line(s, strategy); ok {
+			records = append(records, rec)
+			summary.add(rec)
+		}
 	}
-	p = keys[r]
+	summary.rounds = g.rounds
 	return
 }
 
-func Abs(x int) int {
-	if x < 0 {
-		return -x
+// Replays the lines of a strategy guide already read, as Play does.
+func (g *Game) Replay(lines []string, strategy func(rune, rune) (int, int)) (records []Record, summary Summary) {
+
+	for _, s := range lines {
+		if rec, ok := g.line(s, strategy); ok {
+			records = append(records, rec)
+			summary.add(rec)
+		}
 	}
-	return x
+	summary.rounds = g.rounds
+	return
 }
 
-// First strategy guide
-func strat1(player1 rune, player2 rune) (p1, p2 int) {
+// Plays the round of a single line of a strategy guide, if it's valid.
+func (g *Game) line(s string, strategy func(rune, rune) (int, int)) (rec Record, ok bool) {
 
-	if Abs(pts(player1)) == pts(player2) {
-		p1 = pts(player1) - 3
-		p2 = pts(player2) + 3
+	signs := []rune(s)
+	if len(signs) < 3 {
 		return
 	}
-	if player1 == 'A' {
-		if player2 == 'Y' {
-			// Win
-			p1 = pts(player1)
-			p2 = pts(player2) + 6
-		} else if player2 == 'Z' {
-			// Lose
-			p1 = pts(player1) - 6
-			p2 = pts(player2)
-		}
+	g.player1 = signs[0]
+	g.player2 = signs[2]
+
+	sign1, sign2 := strategy(g.player1, g.player2)
+	if sign1 < 0 || sign2 < 0 {
+		return
 	}
-	if player1 == 'B' {
-		if player2 == 'Z' {
-			// Win
-			p1 = pts(player1)
-			p2 = pts(player2) + 6
-		} else if player2 == 'X' {
-			// Lose
-			p1 = pts(player1) - 6
-			p2 = pts(player2)
+	rec = g.score(sign1, sign2)
+	ok = true
+	return
+}
+
+// Reads the lines of a strategy guide.
+func ReadGuide(filename string, rules *Rules) (lines []string) {
+
+	for line := range NewGame(filename, rules).game {
+		if len([]rune(line)) >= 3 {
+			lines = append(lines, line)
 		}
 	}
-	if player1 == 'C' {
-		if player2 == 'X' {
-			// Win
-			p1 = pts(player1)
-			p2 = pts(player2) + 6
-		} else if player2 == 'Y' {
-			// Lose
-			p1 = pts(player1) - 6
-			p2 = pts(player2)
+	return
+}
+
+// Prints a Record as the round is played.
+func PrintRecord(rec Record) {
+
+	switch rec.outcome {
+	case Win:
+		fmt.Printf("Round %d: %s beats %s, Player1 takes the round...", rec.round, rec.sign1, rec.sign2)
+	case Draw:
+		fmt.Printf("Round %d: %s vs %s, it's a draw...", rec.round, rec.sign1, rec.sign2)
+	default:
+		fmt.Printf("Round %d: %s beats %s, Player2 takes the round...", rec.round, rec.sign2, rec.sign1)
+	}
+	fmt.Printf(" (%d+%d vs %d+%d, totals %d vs %d)\n",
+		rec.shape1, rec.points1, rec.shape2, rec.points2, rec.total1, rec.total2)
+	return
+}
+
+// Runs a round-robin tournament between all the Strategies.
+//
+// Usage: tournament [-rounds N] [-seed S]
+func tournament(rules *Rules, filename string, args []string) {
+
+	cmd := flag.NewFlagSet("tournament", flag.ExitOnError)
+	rounds := cmd.Int("rounds", 1000, "number of rounds per match")
+	seed := cmd.Int64("seed", 1, "seed of the random strategy")
+	cmd.Parse(args)
+
+	var strategies []Strategy
+	for _, name := range StrategyNames {
+		s, err := NewStrategy(name, rules, filename, *seed)
+		if err != nil {
+			panic(err)
 		}
+		strategies = append(strategies, s)
 	}
+	PrintRanking(NewTournament(rules, *rounds, strategies).Play())
 	return
 }
 
-// Second strategy guide
-func strat2(player1 rune, player2 rune) (p1, p2 int) {
+// Decodes the strategy guide by ranking all the meanings of its letters.
+//
+// Usage: decode [-top N]
+func decode(rules *Rules, filename string, args []string) {
 
-	if player2 == 'Y' {
-		// Draw
-		p1 = pts(player1) - 3
-		p2 = Abs(p1)
-		return
+	cmd := flag.NewFlagSet("decode", flag.ExitOnError)
+	top := cmd.Int("top", 10, "number of interpretations to list (0 lists all)")
+	cmd.Parse(args)
+
+	PrintInterpretations(Decode(rules, ReadGuide(filename, rules)), *top)
+	return
+}
+
+// Plays interactively against one of the Strategies.
+//
+// Usage: play [-opponent NAME] [-seed S] [-record FILE]
+func play(rules *Rules, filename string, args []string) {
+
+	cmd := flag.NewFlagSet("play", flag.ExitOnError)
+	name := cmd.String("opponent", "random", "opponent strategy: "+strings.Join(StrategyNames, ", "))
+	seed := cmd.Int64("seed", 1, "seed of the random strategy")
+	path := cmd.String("record", "", "strategy guide file to record the session to")
+	cmd.Parse(args)
+
+	opponent, err := NewStrategy(*name, rules, filename, *seed)
+	if err != nil {
+		panic(err)
 	}
-	if player1 == 'A' {
-		// Rock
-		if player2 == 'Z' {
-			// Win Y
-			player2 = 'Y' // Paper
-			p1 = pts(player1)
-			p2 = pts(player2) + 6
-		} else if player2 == 'X' {
-			// Lose Z
-			player2 = 'Z' // Scissors
-			p1 = pts(player1) - 6
-			p2 = pts(player2)
+	var record io.Writer
+	if *path != "" {
+		f, err := os.Create(*path)
+		if err != nil {
+			panic(err)
 		}
+		defer f.Close()
+		record = f
 	}
-	if player1 == 'B' {
-		// Paper
-		if player2 == 'Z' {
-			// Win
-			player2 = 'Z' // Scissors
-			p1 = pts(player1)
-			p2 = pts(player2) + 6
-		} else if player2 == 'X' {
-			// Lose
-			player2 = 'X' // Rock
-			p1 = pts(player1) - 6
-			p2 = pts(player2)
-		}
+
+	g := NewMatch(rules)
+	g.Observe(PrintRecord)
+	_, summary := g.Interactive(opponent, os.Stdin, os.Stdout, record)
+	summary.Print()
+	return
+}
+
+// Hosts a networked Game for a single opponent.
+//
+// Usage: serve [-addr ADDR] [-rounds N] [-strategy NAME] [-seed S]
+func serve(rules *Rules, filename string, args []string) {
+
+	cmd := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := cmd.String("addr", ":2022", "address to listen on")
+	rounds := cmd.Int("rounds", 10, "number of rounds")
+	name := cmd.String("strategy", "random", "own strategy: "+strings.Join(StrategyNames, ", "))
+	seed := cmd.Int64("seed", 1, "seed of the random strategy")
+	cmd.Parse(args)
+
+	own, err := NewStrategy(*name, rules, filename, *seed)
+	if err != nil {
+		panic(err)
 	}
-	if player1 == 'C' {
-		// Scissors
-		if player2 == 'Z' {
-			// Win
-			player2 = 'X' // Rock
-			p1 = pts(player1)
-			p2 = pts(player2) + 6
-		} else if player2 == 'X' {
-			// Lose
-			player2 = 'Y' // Paper
-			p1 = pts(player1) - 6
-			p2 = pts(player2)
-		}
+	summary, err := Serve(rules, *addr, own, *rounds, func(a net.Addr) {
+		fmt.Printf("Waiting for an opponent on %s...\n", a)
+	})
+	if err != nil {
+		panic(err)
 	}
+	summary.Print()
+	return
+}
+
+// Joins a networked Game.
+//
+// Usage: join [-addr ADDR] [-strategy NAME] [-seed S]
+func join(rules *Rules, filename string, args []string) {
+
+	cmd := flag.NewFlagSet("join", flag.ExitOnError)
+	addr := cmd.String("addr", "localhost:2022", "address of the host")
+	name := cmd.String("strategy", "random", "own strategy: "+strings.Join(StrategyNames, ", "))
+	seed := cmd.Int64("seed", 2, "seed of the random strategy")
+	cmd.Parse(args)
+
+	own, err := NewStrategy(*name, rules, filename, *seed)
+	if err != nil {
+		panic(err)
+	}
+	summary, err := Join(rules, *addr, own)
+	if err != nil {
+		panic(err)
+	}
+	summary.Print()
 	return
 }
 
 func main() {
 
+	name := flag.String("rules", "rps", "built-in rule set (rps, rpsls) or a .json rules file")
+	flag.Parse()
+
+	rules, err := LoadRules(*name)
+	if err != nil {
+		panic(err)
+	}
+	fmt.Printf("Playing %s...\n", rules.Name)
+
 	const filename string = "input.txt"
-	g1 := NewGame(filename)
-	g1.Play(strat1)
-	g2 := NewGame(filename)
-	g2.Play(strat2)
+	switch flag.Arg(0) {
+	case "tournament":
+		tournament(rules, filename, flag.Args()[1:])
+		return
+	case "play":
+		play(rules, filename, flag.Args()[1:])
+		return
+	case "serve":
+		serve(rules, filename, flag.Args()[1:])
+		return
+	case "join":
+		join(rules, filename, flag.Args()[1:])
+		return
+	case "decode":
+		decode(rules, filename, flag.Args()[1:])
+		return
+	}
+
+	g1 := NewGame(filename, rules)
+	g1.Observe(PrintRecord)
+	_, summary := g1.Play(rules.Strat1)
+	summary.Print()
+	g2 := NewGame(filename, rules)
+	g2.Observe(PrintRecord)
+	_, summary = g2.Play(rules.Strat2)
+	summary.Print()
 }
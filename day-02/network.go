@@ -0,0 +1,231 @@
+// Miguel Nobre Castro
+// https://adventofcode.com/2022/day/2
+
+package main
+
+import (
+	"bufio"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+)
+
+// Maximum number of rounds of a networked Game.
+const MAX_ROUNDS int = 1000000
+
+// Peer of a networked Game speaking the line protocol:
+//
+//	server: GAME <rounds> <rules name>
+//	client: READY <strategy name>
+//	every round, both sides:
+//	        COMMIT <sha256 of "<nonce>:<sign>">
+//	        REVEAL <sign> <nonce>
+//	server: BYE
+//
+// Every side commits to its sign before seeing the opponent's commitment and
+// only reveals it once both commitments are exchanged, so that neither side
+// can choose its sign after seeing the other one.
+type Peer struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// Peer constructor over an established connection.
+func NewPeer(conn net.Conn) (peer *Peer) {
+
+	peer = &Peer{
+		conn: conn,
+		r:    bufio.NewReader(conn),
+	}
+	return
+}
+
+// Sends a line to the other Peer.
+func (peer *Peer) send(format string, args ...any) error {
+
+	_, err := fmt.Fprintf(peer.conn, format+"\n", args...)
+	return err
+}
+
+// Receives a line starting with 'verb' from the other Peer and returns its
+// remaining fields.
+func (peer *Peer) expect(verb string) (fields []string, err error) {
+
+	s, err := peer.r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	fields = strings.Fields(s)
+	if len(fields) == 0 || fields[0] != verb {
+		return nil, fmt.Errorf("Remote: expected %s, received '%s'.", verb, strings.TrimSpace(s))
+	}
+	return fields[1:], nil
+}
+
+// Commitment to a sign given a random nonce.
+func commitment(nonce string, sign int) string {
+
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s:%d", nonce, sign)))
+	return hex.EncodeToString(sum[:])
+}
+
+// Exchanges the signs of a round: commits to 'own', then reveals it and
+// verifies the opponent's reveal against its commitment.
+func (peer *Peer) exchange(own int, n int) (opp int, err error) {
+
+	buf := make([]byte, 16)
+	if _, err = rand.Read(buf); err != nil {
+		return
+	}
+	nonce := hex.EncodeToString(buf)
+	if err = peer.send("COMMIT %s", commitment(nonce, own)); err != nil {
+		return
+	}
+	fields, err := peer.expect("COMMIT")
+	if err != nil {
+		return
+	}
+	if len(fields) != 1 {
+		return -1, errors.New("Remote: malformed commitment.")
+	}
+	committed := fields[0]
+
+	if err = peer.send("REVEAL %d %s", own, nonce); err != nil {
+		return
+	}
+	fields, err = peer.expect("REVEAL")
+	if err != nil {
+		return
+	}
+	if len(fields) != 2 {
+		return -1, errors.New("Remote: malformed reveal.")
+	}
+	opp, err = strconv.Atoi(fields[0])
+	if err != nil || opp < 0 || opp >= n {
+		return -1, fmt.Errorf("Remote: revealed an unknown sign '%s'.", fields[0])
+	}
+	if commitment(fields[1], opp) != committed {
+		return -1, errors.New("Remote: revealed sign doesn't match its commitment.")
+	}
+	return
+}
+
+// Plays the 'rounds' of a networked Game with the given Strategy. The host
+// is Player1 and the joining side Player2; both keep the same records.
+func (g *Game) Remote(peer *Peer, own Strategy, host bool, rounds int) (opponent string, records []Record, summary Summary, err error) {
+
+	// Handshake
+	if host {
+		if err = peer.send("GAME %d %s", rounds, g.rules.Name); err != nil {
+			return
+		}
+		var fields []string
+		if fields, err = peer.expect("READY"); err != nil {
+			return
+		}
+		opponent = strings.Join(fields, " ")
+	} else {
+		var fields []string
+		if fields, err = peer.expect("GAME"); err != nil {
+			return
+		}
+		if len(fields) < 2 {
+			err = errors.New("Remote: malformed game.")
+			return
+		}
+		if rounds, err = strconv.Atoi(fields[0]); err != nil {
+			return
+		}
+		if rounds < 0 || rounds > MAX_ROUNDS {
+			err = fmt.Errorf("Remote: host asks for %d rounds, out of 0..%d.", rounds, MAX_ROUNDS)
+			return
+		}
+		if name := strings.Join(fields[1:], " "); name != g.rules.Name {
+			err = fmt.Errorf("Remote: host plays %s, not %s.", name, g.rules.Name)
+			return
+		}
+		if err = peer.send("READY %s", own.Name()); err != nil {
+			return
+		}
+		opponent = "host"
+	}
+
+	own.Reset(g.rules)
+	history := make([]Turn, 0, rounds)
+	for i := 0; i < rounds; i++ {
+		mine := own.Move(history)
+		var theirs int
+		if theirs, err = peer.exchange(mine, len(g.rules.Signs)); err != nil {
+			return
+		}
+		sign1, sign2 := mine, theirs
+		if !host {
+			sign1, sign2 = theirs, mine
+		}
+		rec := g.score(sign1, sign2)
+		records = append(records, rec)
+		summary.add(rec)
+		outcome := rec.outcome
+		if !host {
+			outcome = outcome.Reverse()
+		}
+		history = append(history, Turn{own: mine, opp: theirs, outcome: outcome})
+	}
+	summary.rounds = g.rounds
+
+	if host {
+		err = peer.send("BYE")
+	} else {
+		_, err = peer.expect("BYE")
+	}
+	return
+}
+
+// Hosts a networked Game on 'addr' for a single opponent.
+func Serve(rules *Rules, addr string, own Strategy, rounds int, ready func(net.Addr)) (summary Summary, err error) {
+
+	if rounds < 0 || rounds > MAX_ROUNDS {
+		return summary, fmt.Errorf("Remote: %d rounds are out of 0..%d.", rounds, MAX_ROUNDS)
+	}
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return
+	}
+	defer ln.Close()
+	if ready != nil {
+		ready(ln.Addr())
+	}
+	conn, err := ln.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	g := NewMatch(rules)
+	g.Observe(PrintRecord)
+	opponent, _, summary, err := g.Remote(NewPeer(conn), own, true, rounds)
+	if err == nil {
+		fmt.Printf("Played %d rounds against %s.\n", summary.rounds, opponent)
+	}
+	return
+}
+
+// Joins a networked Game hosted on 'addr'.
+func Join(rules *Rules, addr string, own Strategy) (summary Summary, err error) {
+
+	conn, err := net.Dial("tcp", addr)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	g := NewMatch(rules)
+	g.Observe(PrintRecord)
+	_, _, summary, err = g.Remote(NewPeer(conn), own, false, 0)
+	return
+}
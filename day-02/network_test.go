@@ -0,0 +1,135 @@
+// Miguel Nobre Castro
+// https://adventofcode.com/2022/day/2
+
+package main
+
+import (
+	"net"
+	"strings"
+	"testing"
+)
+
+// Loads the built-in Rules of a test.
+func rps(t *testing.T) *Rules {
+
+	t.Helper()
+	rules, err := LoadRules("rps")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rules
+}
+
+func TestServeJoin(t *testing.T) {
+
+	rules := rps(t)
+	host, err := NewStrategy("markov", rules, "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	guest, err := NewStrategy("random", rules, "", 7)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	addr := make(chan string, 1)
+	served := make(chan Summary, 1)
+	errs := make(chan error, 1)
+	go func() {
+		summary, err := Serve(rules, "127.0.0.1:0", host, 25, func(a net.Addr) { addr <- a.String() })
+		if err != nil {
+			// Unblock the joining side if the host fails before listening
+			close(addr)
+		}
+		errs <- err
+		served <- summary
+	}()
+	a, ok := <-addr
+	if !ok {
+		t.Fatal(<-errs)
+	}
+	joined, err := Join(rules, a, guest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errs; err != nil {
+		t.Fatal(err)
+	}
+	summary := <-served
+	if summary != joined {
+		t.Errorf("Host summary %+v, joining side %+v", summary, joined)
+	}
+	if summary.rounds != 25 {
+		t.Errorf("Played %d rounds, want 25", summary.rounds)
+	}
+}
+
+// Hosts a Game on loopback speaking the protocol through 'host', and joins it.
+func joinFake(t *testing.T, rules *Rules, host func(peer *Peer) error) error {
+
+	t.Helper()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	done := make(chan error, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			done <- err
+			return
+		}
+		defer conn.Close()
+		done <- host(NewPeer(conn))
+	}()
+
+	guest, err := NewStrategy("frequency", rules, "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = Join(rules, ln.Addr().String(), guest)
+	<-done
+	return err
+}
+
+func TestJoinTamperedReveal(t *testing.T) {
+
+	rules := rps(t)
+	err := joinFake(t, rules, func(peer *Peer) error {
+		if err := peer.send("GAME 1 %s", rules.Name); err != nil {
+			return err
+		}
+		if _, err := peer.expect("READY"); err != nil {
+			return err
+		}
+		// Commits to sign 0 but reveals sign 1
+		if err := peer.send("COMMIT %s", commitment("nonce", 0)); err != nil {
+			return err
+		}
+		if _, err := peer.expect("COMMIT"); err != nil {
+			return err
+		}
+		if err := peer.send("REVEAL 1 nonce"); err != nil {
+			return err
+		}
+		_, err := peer.expect("REVEAL")
+		return err
+	})
+	if err == nil || !strings.Contains(err.Error(), "doesn't match its commitment") {
+		t.Errorf("Join accepted a tampered reveal: %v", err)
+	}
+}
+
+func TestJoinInvalidRounds(t *testing.T) {
+
+	rules := rps(t)
+	for _, rounds := range []string{"-1", "1000000000000"} {
+		err := joinFake(t, rules, func(peer *Peer) error {
+			return peer.send("GAME %s %s", rounds, rules.Name)
+		})
+		if err == nil || !strings.Contains(err.Error(), "rounds") {
+			t.Errorf("Join accepted %s rounds: %v", rounds, err)
+		}
+	}
+}
@@ -0,0 +1,243 @@
+// Miguel Nobre Castro
+// https://adventofcode.com/2022/day/2
+
+package main
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Built-in rule sets shipped with the game.
+//
+//go:embed rules/*.json
+var builtin embed.FS
+
+// Rules of a cyclic hand game declared in a JSON file.
+//
+// Rules file example:
+//
+//	{
+//		"name": "Rock-Paper-Scissors",
+//		"signs": ["Rock", "Paper", "Scissors"],
+//		"beats": {"Rock": ["Scissors"], "Paper": ["Rock"], "Scissors": ["Paper"]},
+//		"shapes": {"Rock": 1, "Paper": 2, "Scissors": 3},
+//		"outcomes": {"lose": 0, "draw": 3, "win": 6},
+//		"player1": "ABC",
+//		"player2": "XYZ"
+//	}
+//
+// The "beats" relation is optional: by default each sign beats the (N-1)/2
+// signs listed right before it. The letters of "player1" and "player2" are
+// the input letters of each sign, in the order of "signs".
+type Rules struct {
+	Name     string              `json:"name"`
+	Signs    []string            `json:"signs"`
+	Beats    map[string][]string `json:"beats"`
+	Shapes   map[string]int      `json:"shapes"`
+	Outcomes struct {
+		Lose int `json:"lose"`
+		Draw int `json:"draw"`
+		Win  int `json:"win"`
+	} `json:"outcomes"`
+	Player1 string `json:"player1"`
+	Player2 string `json:"player2"`
+
+	beats   [][]bool     // beats[i][j] if sign i beats sign j
+	scores  []int        // Shape score of each sign
+	letter1 map[rune]int // Player1 letter to sign
+	letter2 map[rune]int // Player2 letter to sign
+}
+
+// Loads the Rules from a JSON file, or a built-in rule set given its name
+// ("rps" or "rpsls").
+func LoadRules(name string) (rules *Rules, err error) {
+
+	var data []byte
+	if !strings.HasSuffix(name, ".json") {
+		data, err = builtin.ReadFile("rules/" + name + ".json")
+	} else {
+		data, err = os.ReadFile(name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	rules = &Rules{}
+	if err = json.Unmarshal(data, rules); err != nil {
+		return nil, err
+	}
+	if err = rules.compile(); err != nil {
+		return nil, err
+	}
+	return
+}
+
+// Validates the Rules and builds the lookup tables.
+func (rules *Rules) compile() error {
+
+	n := len(rules.Signs)
+	if n < 3 || n%2 == 0 {
+		return fmt.Errorf("Rules: %d signs, an odd number of at least 3 is required.", n)
+	}
+	idx := make(map[string]int, n)
+	for i, sign := range rules.Signs {
+		if _, ok := idx[sign]; ok {
+			return fmt.Errorf("Rules: sign '%s' is declared twice.", sign)
+		}
+		idx[sign] = i
+	}
+
+	// Beats relation
+	rules.beats = make([][]bool, n)
+	for i := range rules.beats {
+		rules.beats[i] = make([]bool, n)
+	}
+	if rules.Beats == nil {
+		for i := 0; i < n; i++ {
+			for k := 1; k <= (n-1)/2; k++ {
+				rules.beats[i][(i-k+n)%n] = true
+			}
+		}
+	} else {
+		for sign, losers := range rules.Beats {
+			i, ok := idx[sign]
+			if !ok {
+				return fmt.Errorf("Rules: unknown sign '%s' in beats.", sign)
+			}
+			for _, loser := range losers {
+				j, ok := idx[loser]
+				if !ok {
+					return fmt.Errorf("Rules: unknown sign '%s' in beats.", loser)
+				}
+				rules.beats[i][j] = true
+			}
+		}
+	}
+	for i := 0; i < n; i++ {
+		wins := 0
+		for j := 0; j < n; j++ {
+			if i == j && rules.beats[i][j] {
+				return fmt.Errorf("Rules: sign '%s' beats itself.", rules.Signs[i])
+			}
+			if i != j && rules.beats[i][j] == rules.beats[j][i] {
+				return fmt.Errorf("Rules: '%s' vs '%s' has no single winner.", rules.Signs[i], rules.Signs[j])
+			}
+			if rules.beats[i][j] {
+				wins += 1
+			}
+		}
+		if wins != (n-1)/2 {
+			return fmt.Errorf("Rules: sign '%s' beats %d signs instead of %d.", rules.Signs[i], wins, (n-1)/2)
+		}
+	}
+
+	// Shape scores, defaulting to the position of the sign
+	rules.scores = make([]int, n)
+	for i, sign := range rules.Signs {
+		rules.scores[i] = i + 1
+		if score, ok := rules.Shapes[sign]; ok {
+			rules.scores[i] = score
+		}
+	}
+
+	// Input letters
+	var err error
+	if rules.letter1, err = letters(rules.Player1, n); err != nil {
+		return err
+	}
+	if rules.letter2, err = letters(rules.Player2, n); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Maps each input letter to its sign.
+func letters(s string, n int) (keys map[rune]int, err error) {
+
+	if len([]rune(s)) != n {
+		return nil, fmt.Errorf("Rules: letters '%s' don't match the %d signs.", s, n)
+	}
+	keys = make(map[rune]int, n)
+	for i, r := range []rune(s) {
+		if _, ok := keys[r]; ok {
+			return nil, fmt.Errorf("Rules: letter %c is declared twice.", r)
+		}
+		keys[r] = i
+	}
+	return
+}
+
+// Outcome of a round for the player throwing 'sign1' against 'sign2'.
+func (rules *Rules) Outcome(sign1 int, sign2 int) Outcome {
+
+	if rules.beats[sign1][sign2] {
+		return Win
+	} else if rules.beats[sign2][sign1] {
+		return Lose
+	}
+	return Draw
+}
+
+// Points awarded for an Outcome.
+func (rules *Rules) Points(o Outcome) (p int) {
+
+	switch o {
+	case Win:
+		p = rules.Outcomes.Win
+	case Draw:
+		p = rules.Outcomes.Draw
+	case Lose:
+		p = rules.Outcomes.Lose
+	}
+	return
+}
+
+// Finds the sign Player2 throws for the 'k'-th outcome letter against 'sign1'.
+// The middle letter draws, the following ones pick the 1st, 2nd, ... sign that
+// beats 'sign1' and the preceding ones the 1st, 2nd, ... sign it beats.
+func (rules *Rules) Respond(sign1 int, k int) (sign2 int) {
+
+	n := len(rules.Signs)
+	d := k - (n-1)/2
+	sign2 = sign1
+	step := 1
+	if d < 0 {
+		d = -d
+		step = -1
+	}
+	for d > 0 {
+		sign2 = (sign2 + step + n) % n
+		if (step > 0 && rules.beats[sign2][sign1]) || (step < 0 && rules.beats[sign1][sign2]) {
+			d -= 1
+		}
+	}
+	return
+}
+
+// First strategy guide: the second letter is the sign to throw. Unknown
+// letters decode to -1.
+func (rules *Rules) Strat1(player1 rune, player2 rune) (sign1, sign2 int) {
+
+	sign1, sign2 = -1, -1
+	if s, ok := rules.letter1[player1]; ok {
+		sign1 = s
+	}
+	if s, ok := rules.letter2[player2]; ok {
+		sign2 = s
+	}
+	return
+}
+
+// Second strategy guide: the second letter is how the round must end.
+func (rules *Rules) Strat2(player1 rune, player2 rune) (sign1, sign2 int) {
+
+	sign1, k := rules.Strat1(player1, player2)
+	sign2 = -1
+	if sign1 >= 0 && k >= 0 {
+		sign2 = rules.Respond(sign1, k)
+	}
+	return
+}
@@ -0,0 +1,234 @@
+// Miguel Nobre Castro
+// https://adventofcode.com/2022/day/2
+
+package main
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// Turn of a round as seen by one of the players.
+type Turn struct {
+	own     int     // Sign thrown by the player
+	opp     int     // Sign thrown by the opponent
+	outcome Outcome // Outcome for the player
+}
+
+// Strategy of a player choosing its sign given the history of the match.
+type Strategy interface {
+	Name() string
+	Reset(rules *Rules)             // Called before every match
+	Move(history []Turn) (sign int) // Called every round
+}
+
+// Finds the first sign that beats 'sign'.
+func (rules *Rules) Counter(sign int) int {
+
+	return rules.Respond(sign, (len(rules.Signs)-1)/2+1)
+}
+
+// Sign 'sign' is thrown the most in 'counts', the first one on ties.
+func argmax(counts []int) (sign int) {
+
+	for i := range counts {
+		if counts[i] > counts[sign] {
+			sign = i
+		}
+	}
+	return
+}
+
+// Frequency counter: beats the sign the opponent throws the most.
+type Frequency struct {
+	rules  *Rules
+	counts []int
+}
+
+func (s *Frequency) Name() string { return "frequency" }
+
+func (s *Frequency) Reset(rules *Rules) {
+
+	s.rules = rules
+	s.counts = make([]int, len(rules.Signs))
+}
+
+func (s *Frequency) Move(history []Turn) int {
+
+	if len(history) == 0 {
+		return 0
+	}
+	s.counts[history[len(history)-1].opp] += 1
+	return s.rules.Counter(argmax(s.counts))
+}
+
+// Markov predictor: beats the sign the opponent most often throws after its
+// last sign.
+type Markov struct {
+	rules       *Rules
+	transitions [][]int
+}
+
+func (s *Markov) Name() string { return "markov" }
+
+func (s *Markov) Reset(rules *Rules) {
+
+	s.rules = rules
+	s.transitions = make([][]int, len(rules.Signs))
+	for i := range s.transitions {
+		s.transitions[i] = make([]int, len(rules.Signs))
+	}
+}
+
+func (s *Markov) Move(history []Turn) int {
+
+	n := len(history)
+	if n == 0 {
+		return 0
+	}
+	if n > 1 {
+		s.transitions[history[n-2].opp][history[n-1].opp] += 1
+	}
+	return s.rules.Counter(argmax(s.transitions[history[n-1].opp]))
+}
+
+// Win-stay/lose-shift: keeps the sign after a win, otherwise moves on to the
+// next sign.
+type WinStay struct {
+	rules *Rules
+}
+
+func (s *WinStay) Name() string { return "win-stay" }
+
+func (s *WinStay) Reset(rules *Rules) {
+
+	s.rules = rules
+}
+
+func (s *WinStay) Move(history []Turn) int {
+
+	if len(history) == 0 {
+		return 0
+	}
+	last := history[len(history)-1]
+	if last.outcome == Win {
+		return last.own
+	}
+	return (last.own + 1) % len(s.rules.Signs)
+}
+
+// Random: throws uniformly random signs from a seeded source, so that every
+// match is reproducible.
+type Random struct {
+	seed int64
+	n    int
+	rng  *rand.Rand
+}
+
+// Random strategy constructor.
+func NewRandom(seed int64) (s *Random) {
+
+	s = &Random{seed: seed}
+	return
+}
+
+func (s *Random) Name() string { return fmt.Sprintf("random(%d)", s.seed) }
+
+func (s *Random) Reset(rules *Rules) {
+
+	s.n = len(rules.Signs)
+	s.rng = rand.New(rand.NewSource(s.seed))
+}
+
+func (s *Random) Move(history []Turn) int {
+
+	return s.rng.Intn(s.n)
+}
+
+// Guide adapter: replays the Player2 signs of a strategy guide, decoding each
+// line with one of the guides ('Rules.Strat1' or 'Rules.Strat2'). The guide
+// is replayed from the start once all its lines are played.
+type Guide struct {
+	name   string
+	lines  []string
+	decode func(*Rules, rune, rune) (int, int)
+	rules  *Rules
+}
+
+// Guide adapter constructor given the guide file and its decoder.
+func NewGuide(name string, filename string, rules *Rules, decode func(*Rules, rune, rune) (int, int)) (s *Guide) {
+
+	s = &Guide{
+		name:   name,
+		lines:  ReadGuide(filename, rules),
+		decode: decode,
+	}
+	return
+}
+
+func (s *Guide) Name() string { return s.name }
+
+func (s *Guide) Reset(rules *Rules) {
+
+	s.rules = rules
+}
+
+func (s *Guide) Move(history []Turn) int {
+
+	if len(s.lines) == 0 {
+		return 0
+	}
+	signs := []rune(s.lines[len(history)%len(s.lines)])
+	_, sign := s.decode(s.rules, signs[0], signs[2])
+	if sign < 0 {
+		return 0
+	}
+	return sign
+}
+
+// Plays a match of 'rounds' rounds between two Strategies, scored by the
+// Game's Rules.
+func (g *Game) Match(s1 Strategy, s2 Strategy, rounds int) (records []Record, summary Summary) {
+
+	s1.Reset(g.rules)
+	s2.Reset(g.rules)
+	history1 := make([]Turn, 0, rounds)
+	history2 := make([]Turn, 0, rounds)
+	for i := 0; i < rounds; i++ {
+		sign1 := s1.Move(history1)
+		sign2 := s2.Move(history2)
+		rec := g.score(sign1, sign2)
+		history1 = append(history1, Turn{own: sign1, opp: sign2, outcome: rec.outcome})
+		history2 = append(history2, Turn{own: sign2, opp: sign1, outcome: rec.outcome.Reverse()})
+		records = append(records, rec)
+		summary.add(rec)
+	}
+	summary.rounds = rounds
+	return
+}
+
+// Names of the Strategies available to NewStrategy.
+var StrategyNames = []string{"frequency", "markov", "win-stay", "random", "guide1", "guide2"}
+
+// Creates a Strategy given its name. The guides replay the strategy guide in
+// 'filename' and the random Strategy is seeded with 'seed'.
+func NewStrategy(name string, rules *Rules, filename string, seed int64) (s Strategy, err error) {
+
+	switch name {
+	case "frequency":
+		s = &Frequency{}
+	case "markov":
+		s = &Markov{}
+	case "win-stay":
+		s = &WinStay{}
+	case "random":
+		s = NewRandom(seed)
+	case "guide1":
+		s = NewGuide(name, filename, rules, (*Rules).Strat1)
+	case "guide2":
+		s = NewGuide(name, filename, rules, (*Rules).Strat2)
+	default:
+		err = fmt.Errorf("Strategy: unknown strategy '%s'.", name)
+	}
+	return
+}
@@ -0,0 +1,92 @@
+// Miguel Nobre Castro
+// https://adventofcode.com/2022/day/2
+
+package main
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Standing of a Strategy in a Tournament.
+type Standing struct {
+	name   string
+	played int
+	wins   int // Matches won
+	draws  int // Matches drawn
+	losses int // Matches lost
+	points int // 3 points per match won, 1 per match drawn
+	score  int // Game score summed over all matches
+}
+
+// Round-robin Tournament between Strategies.
+type Tournament struct {
+	rules      *Rules
+	rounds     int
+	strategies []Strategy
+	standings  []*Standing
+}
+
+// Tournament constructor.
+func NewTournament(rules *Rules, rounds int, strategies []Strategy) (t *Tournament) {
+
+	t = &Tournament{
+		rules:      rules,
+		rounds:     rounds,
+		strategies: strategies,
+	}
+	for _, s := range strategies {
+		t.standings = append(t.standings, &Standing{name: s.Name()})
+	}
+	return
+}
+
+// Plays every pair of Strategies once and ranks them by points, then score.
+func (t *Tournament) Play() (ranking []*Standing) {
+
+	for i := 0; i < len(t.strategies); i++ {
+		for j := i + 1; j < len(t.strategies); j++ {
+			_, summary := NewMatch(t.rules).Match(t.strategies[i], t.strategies[j], t.rounds)
+			t.standings[i].add(summary.Outcome(), summary.player1.score)
+			t.standings[j].add(summary.Outcome().Reverse(), summary.player2.score)
+			fmt.Printf("%s - %d vs %d - %s\n", t.strategies[i].Name(), summary.player1.score, summary.player2.score, t.strategies[j].Name())
+		}
+	}
+
+	ranking = append(ranking, t.standings...)
+	sort.SliceStable(ranking, func(a, b int) bool {
+		if ranking[a].points != ranking[b].points {
+			return ranking[a].points > ranking[b].points
+		}
+		return ranking[a].score > ranking[b].score
+	})
+	return
+}
+
+// Adds a match result to the Standing.
+func (st *Standing) add(o Outcome, score int) {
+
+	st.played += 1
+	st.score += score
+	switch o {
+	case Win:
+		st.wins += 1
+		st.points += 3
+	case Draw:
+		st.draws += 1
+		st.points += 1
+	case Lose:
+		st.losses += 1
+	}
+	return
+}
+
+// Prints the ranking of a Tournament.
+func PrintRanking(ranking []*Standing) {
+
+	fmt.Printf("%-4s %-16s %6s %4s %4s %4s %6s %8s\n", "Rank", "Strategy", "Played", "W", "D", "L", "Points", "Score")
+	for i, st := range ranking {
+		fmt.Printf("%-4d %-16s %6d %4d %4d %4d %6d %8d\n", i+1, st.name, st.played, st.wins, st.draws, st.losses, st.points, st.score)
+	}
+	return
+}
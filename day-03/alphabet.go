@@ -0,0 +1,208 @@
+// Miguel Nobre Castro
+// https://adventofcode.com/2022/day/3
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math/bits"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Alphabet of item types and their priorities.
+type Alphabet struct {
+	items      []rune       // Item types, in order of position
+	priorities []int        // Priority of each item type
+	index      map[rune]int // Position of each item type
+}
+
+// Alphabet constructor given the item types and a priority function.
+func NewAlphabet(items []rune, priority func(rune) int) (alphabet *Alphabet) {
+
+	alphabet = &Alphabet{
+		index: make(map[rune]int, len(items)),
+	}
+	for _, r := range items {
+		alphabet.add(r, priority(r))
+	}
+	return
+}
+
+// Default Alphabet of the puzzle: a-z and A-Z (see Priority).
+func DefaultAlphabet() (alphabet *Alphabet) {
+
+	items := make([]rune, 0, 52)
+	for r := 'a'; r <= 'z'; r++ {
+		items = append(items, r)
+	}
+	for r := 'A'; r <= 'Z'; r++ {
+		items = append(items, r)
+	}
+	alphabet = NewAlphabet(items, Priority)
+	return
+}
+
+// Loads an Alphabet from a priority table file.
+//
+// Priority table file example:
+// "
+// a 1
+// b 2
+// é 3
+// "
+func LoadAlphabet(filename string) (alphabet *Alphabet, err error) {
+
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	alphabet = &Alphabet{
+		index: make(map[rune]int),
+	}
+	line := 0
+	r := bufio.NewReader(f)
+	for true {
+		s, err := r.ReadString('\n')
+		if len(s) > 0 {
+			line += 1
+			fields := strings.Fields(s)
+			if len(fields) == 0 {
+				continue
+			}
+			if len(fields) != 2 || utf8.RuneCountInString(fields[0]) != 1 {
+				return nil, fmt.Errorf("Alphabet: line %d: expected '<item> <priority>'.", line)
+			}
+			item, _ := utf8.DecodeRuneInString(fields[0])
+			priority, perr := strconv.Atoi(fields[1])
+			if perr != nil {
+				return nil, fmt.Errorf("Alphabet: line %d: invalid priority '%s'.", line, fields[1])
+			}
+			if _, ok := alphabet.index[item]; ok {
+				return nil, fmt.Errorf("Alphabet: line %d: item %c is declared twice.", line, item)
+			}
+			alphabet.add(item, priority)
+		}
+		if errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+	}
+	return alphabet, nil
+}
+
+// Adds an item type to the Alphabet.
+func (alphabet *Alphabet) add(item rune, priority int) {
+
+	alphabet.index[item] = len(alphabet.items)
+	alphabet.items = append(alphabet.items, item)
+	alphabet.priorities = append(alphabet.priorities, priority)
+	return
+}
+
+// Priority of an item type, or 0 if it's not in the Alphabet.
+func (alphabet *Alphabet) Priority(r rune) int {
+
+	if i, ok := alphabet.index[r]; ok {
+		return alphabet.priorities[i]
+	}
+	return 0
+}
+
+// Whether an item type is in the Alphabet.
+func (alphabet *Alphabet) Has(r rune) bool {
+
+	_, ok := alphabet.index[r]
+	return ok
+}
+
+// Item types of a sack that are not in the Alphabet, in order of appearance.
+func (alphabet *Alphabet) Unknown(items []rune) (unknown []rune) {
+
+	seen := make(map[rune]bool)
+	for _, r := range items {
+		if !alphabet.Has(r) && !seen[r] {
+			seen[r] = true
+			unknown = append(unknown, r)
+		}
+	}
+	return
+}
+
+// Set of item types, one bit per position in an Alphabet.
+type ItemSet []uint64
+
+// Empty ItemSet of an Alphabet.
+func (alphabet *Alphabet) Empty() ItemSet {
+
+	return make(ItemSet, (len(alphabet.items)+63)/64)
+}
+
+// ItemSet of all the item types of an Alphabet.
+func (alphabet *Alphabet) All() (set ItemSet) {
+
+	set = alphabet.Empty()
+	for i := range alphabet.items {
+		set[i/64] |= 1 << (i % 64)
+	}
+	return
+}
+
+// ItemSet of the known items of a sack.
+func (alphabet *Alphabet) Set(items []rune) (set ItemSet) {
+
+	set = alphabet.Empty()
+	for _, r := range items {
+		if i, ok := alphabet.index[r]; ok {
+			set[i/64] |= 1 << (i % 64)
+		}
+	}
+	return
+}
+
+// Item types in the ItemSet, in order of position in the Alphabet.
+func (alphabet *Alphabet) Items(set ItemSet) (items []rune) {
+
+	for w, word := range set {
+		for word != 0 {
+			b := bits.TrailingZeros64(word)
+			items = append(items, alphabet.items[w*64+b])
+			word &= word - 1
+		}
+	}
+	return
+}
+
+// Item types in both sets.
+func (set ItemSet) And(other ItemSet) ItemSet {
+
+	out := make(ItemSet, len(set))
+	for i := range set {
+		out[i] = set[i] & other[i]
+	}
+	return out
+}
+
+// Item types found in all the 'c' equal compartments of a sack.
+func (alphabet *Alphabet) Compartments(items []rune, c int) (common ItemSet, ok bool) {
+
+	if c <= 0 || len(items)%c != 0 {
+		return alphabet.Empty(), false
+	}
+	size := len(items) / c
+	common = alphabet.All()
+	i := 0
+	for i < c {
+		common = common.And(alphabet.Set(items[i*size : (i+1)*size]))
+		i += 1
+	}
+	return common, true
+}
@@ -0,0 +1,46 @@
+// Miguel Nobre Castro
+// https://adventofcode.com/2022/day/3
+
+package main
+
+import "sync"
+
+// Fans out the values of a generator to 'n' subscribers, so that independent
+// consumers (e.g. Part1 and Part2) share a single pass over the input. Every
+// subscriber receives all the values in order and must drain its channel
+// concurrently with the others, as each value is handed to all of them before
+// the next one is read.
+func FanOut[T any](in <-chan T, n int) (outs []chan T) {
+
+	outs = make([]chan T, n)
+	for i := range outs {
+		outs[i] = make(chan T, 1)
+	}
+	go func() {
+		for val := range in {
+			for _, out := range outs {
+				out <- val
+			}
+		}
+		for _, out := range outs {
+			close(out)
+		}
+	}()
+	return
+}
+
+// Runs the consumers of a fanned out generator concurrently and waits for all
+// of them to finish.
+func Consume(consumers ...func()) {
+
+	var wg sync.WaitGroup
+	for _, consumer := range consumers {
+		wg.Add(1)
+		go func(consume func()) {
+			defer wg.Done()
+			consume()
+		}(consumer)
+	}
+	wg.Wait()
+	return
+}
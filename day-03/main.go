@@ -6,25 +6,37 @@ package main
 import (
 	"bufio"
 	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"os"
-	"sort"
 )
 
+// Sack of items read from a line of the input.
+type Sack struct {
+	line  int    // Line number in the input
+	items []rune // Items carried, one rune each
+}
+
 // SackScanner class.
 type SackScanner struct {
-	num    int         // Number of scanned sacks
-	sum    int         // Sum of item priorities
-	badges int         // Sum of badge priorities
-	sack   chan string // Sack generator
+	num          int       // Number of scanned sacks
+	sum          int       // Sum of item priorities
+	badges       int       // Sum of badge priorities
+	swaps        int       // Number of planned item swaps
+	compartments int       // Number of compartments per sack
+	group        int       // Number of Elves per group
+	alphabet     *Alphabet // Item types and their priorities
+	sack         chan Sack // Sack generator
 }
 
-// Constructor of SackScanner from strings using Channels.
-func NewSackScanner(filename string) (scan *SackScanner) {
+// Constructor of SackScanner from strings using Channels, given the Alphabet
+// of the items and the number of compartments in each sack and of Elves in
+// each group.
+func NewSackScanner(filename string, alphabet *Alphabet, compartments int, group int) (scan *SackScanner) {
 
 	// A Generator per rucksack
-	sack := make(chan string, 1)
+	sack := make(chan Sack, 1)
 	buffer := ""
 	go func() {
 		f, err := os.Open(filename)
@@ -32,10 +44,12 @@ func NewSackScanner(filename string) (scan *SackScanner) {
 			defer close(sack)
 		}
 
+		line := 0
 		r := bufio.NewReader(f)
 		for true {
 			s, err := r.ReadString('\n')
 			if !errors.Is(err, io.EOF) {
+				line += 1
 				s = s[:len(s)-1]
 				if len(s) > 0 {
 					buffer += s
@@ -46,16 +60,36 @@ func NewSackScanner(filename string) (scan *SackScanner) {
 				defer close(sack)
 				break
 			}
-			sack <- buffer
+			sack <- Sack{line: line, items: []rune(buffer)}
 			buffer = ""
 		}
 		f.Close()
 	}()
 
 	scan = &SackScanner{
-		num:  0,
-		sum:  0,
-		sack: sack,
+		num:          0,
+		sum:          0,
+		compartments: compartments,
+		group:        group,
+		alphabet:     alphabet,
+		sack:         sack,
+	}
+	return
+}
+
+// Splits the SackScanner into 'n' SackScanners fed by a single pass over its
+// sacks, one per independent analysis (see FanOut).
+func (scan *SackScanner) Tee(n int) (scans []*SackScanner) {
+
+	for _, sack := range FanOut(scan.sack, n) {
+		scans = append(scans, &SackScanner{
+			num:          0,
+			sum:          0,
+			compartments: scan.compartments,
+			group:        scan.group,
+			alphabet:     scan.alphabet,
+			sack:         sack,
+		})
 	}
 	return
 }
@@ -72,136 +106,101 @@ func Priority(r rune) (i int) {
 	return
 }
 
-// Inspects both compartments in each rucksack.
+// Inspects the compartments in each rucksack.
 func (scan *SackScanner) InspectAll() {
 
-	comp1 := "" // First compartment
-	comp2 := "" // Second compartment
 	for sack := range scan.sack {
-		comp1 += SortString(sack[:len(sack)/2])
-		comp2 += SortString(sack[len(sack)/2:])
-
-		size := len(comp1)
-		i, j := 0, 0
-		r := '0'
-		for i != size && j != size {
-			if comp1[i] == comp2[j] && r != rune(comp1[i]) {
-				r = rune(comp1[i])
-				val := Priority(r)
-				scan.sum += val
-				fmt.Printf("Found item %c with priority %d\n", r, val)
-				break
-			}
-
-			if int(comp1[i]-'0') <= int(comp2[j]-'0') {
-				if i < size {
-					i += 1
-				} else {
-					break
-				}
-			} else {
-				if j < size {
-					j += 1
-				} else {
-					break
-				}
-			}
+		common, ok := scan.alphabet.Compartments(sack.items, scan.compartments)
+		if !ok {
+			fmt.Printf("Sack on line %d can't be split into %d compartments\n", sack.line, scan.compartments)
+		}
+		for _, r := range scan.alphabet.Items(common) {
+			val := scan.alphabet.Priority(r)
+			scan.sum += val
+			fmt.Printf("Found item %c with priority %d\n", r, val)
 		}
-
-		comp1 = ""
-		comp2 = ""
 		scan.num += 1
 	}
 }
 
-// Finds the Badge among each three consecutive rucksacks
+// Finds the Badge among each group of consecutive rucksacks
 func (scan *SackScanner) FindBadges() {
 
-	sacks := [3]string{"", "", ""} // Elfs' items
-	trio := 0
+	common := scan.alphabet.All() // Items shared by the group
+	member := 0
 	for sack := range scan.sack {
-		sacks[trio] = SortString(sack)
-
-		if trio == 2 {
-			i, j, k := 0, 0, 0
-			r := '0'
-			for i != len(sacks[0]) && j != len(sacks[1]) && k != len(sacks[2]) {
-				if sacks[0][i] == sacks[1][j] && sacks[0][i] == sacks[2][k] && r != rune(sacks[0][i]) {
-					r = rune(sacks[0][i])
-					val := Priority(r)
-					scan.badges += val
-					fmt.Printf("Found badge %c with priority %d\n", r, val)
-					break
-				}
+		common = common.And(scan.alphabet.Set(sack.items))
+		member += 1
 
-				if int(sacks[0][i]-'0') <= int(sacks[1][j]-'0') && int(sacks[0][i]-'0') <= int(sacks[2][k]-'0') {
-					if i < len(sacks[0]) {
-						i += 1
-					} else {
-						break
-					}
-				} else if int(sacks[1][j]-'0') <= int(sacks[2][k]-'0') {
-					if j < len(sacks[1]) {
-						j += 1
-					} else {
-						break
-					}
-				} else {
-					if k < len(sacks[2]) {
-						k += 1
-					} else {
-						break
-					}
-				}
+		if member == scan.group {
+			badges := scan.alphabet.Items(common)
+			if len(badges) != 1 {
+				fmt.Printf("Group ending on line %d has %d badges\n", sack.line, len(badges))
 			}
-			sacks = [3]string{"", "", ""}
-		}
-		if trio == 2 {
-			trio = 0
-		} else {
-			trio += 1
+			for _, r := range badges {
+				val := scan.alphabet.Priority(r)
+				scan.badges += val
+				fmt.Printf("Found badge %c with priority %d\n", r, val)
+			}
+			common = scan.alphabet.All()
+			member = 0
 		}
 		scan.num += 1
 	}
 }
 
-// A slice of Rune type to enable sort
-type RuneSlice []rune
-
-func (s RuneSlice) Len() (l int) {
+// Validates the items of each rucksack against the Alphabet, reporting the
+// unknown item types with their line.
+func (scan *SackScanner) Validate() (errs []error) {
 
-	l = len(s)
-	return
-}
-
-func (s RuneSlice) Less(i int, j int) (b bool) {
-
-	b = s[i] < s[j]
-	return
-}
-
-func (s RuneSlice) Swap(i int, j int) {
-
-	s[i], s[j] = s[j], s[i]
-	return
-}
-
-// Sort a string by converting it to a slice of Rune
-func SortString(in string) (out string) {
-
-	r := []rune(in)
-	sort.Sort(RuneSlice(r))
-	out = string(r)
+	for sack := range scan.sack {
+		for _, r := range scan.alphabet.Unknown(sack.items) {
+			errs = append(errs, fmt.Errorf("Line %d: unknown item %q.", sack.line, r))
+		}
+		scan.num += 1
+	}
 	return
 }
 
 func main() {
 
+	compartments := flag.Int("c", 2, "number of compartments per sack")
+	group := flag.Int("k", 3, "number of Elves per group")
+	plan := flag.Bool("plan", false, "plan the reorganization of the sacks")
+	format := flag.String("report", "", "print the inventory report as text or json")
+	table := flag.String("alphabet", "", "priority table file of the item types (a-z, A-Z by default)")
+	flag.Parse()
+
+	alphabet := DefaultAlphabet()
+	if *table != "" {
+		var err error
+		if alphabet, err = LoadAlphabet(*table); err != nil {
+			panic(err)
+		}
+	}
+
 	const filename string = "input.txt"
-	scan1 := NewSackScanner(filename)
-	scan1.InspectAll()
-	fmt.Printf("The sum of priorities of these item types is %d.\n", scan1.sum)
-	scan2 := NewSackScanner(filename)
-	scan2.FindBadges()
-	fmt.Printf("The sum of priorities of all bages is %d.\n", scan2.badges)
+	if *format != "" {
+		report := NewSackScanner(filename, alphabet, *compartments, *group).Report()
+		if *format == "json" {
+			if err := report.JSON(os.Stdout); err != nil {
+				panic(err)
+			}
+		} else {
+			report.Print(os.Stdout)
+		}
+		return
+	}
+	if *plan {
+		NewSackScanner(filename, alphabet, *compartments, *group).Plan()
+		return
+	}
+	var errs []error
+	scans := NewSackScanner(filename, alphabet, *compartments, *group).Tee(3)
+	Consume(scans[0].InspectAll, scans[1].FindBadges, func() { errs = scans[2].Validate() })
+	for _, err := range errs {
+		fmt.Println(err)
+	}
+	fmt.Printf("The sum of priorities of these item types is %d.\n", scans[0].sum)
+	fmt.Printf("The sum of priorities of all bages is %d.\n", scans[1].badges)
 }
@@ -0,0 +1,219 @@
+// Miguel Nobre Castro
+// https://adventofcode.com/2022/day/3
+
+package main
+
+import "fmt"
+
+// Move of items of a type out of a sack.
+type Move struct {
+	item  rune
+	count int
+	from  int // Source compartment, starting at 1
+	to    int // Target compartment, starting at 1
+}
+
+// Counts the items of each type in each of the 'c' equal compartments.
+func CountItems(sack []rune, c int) (counts map[rune][]int, ok bool) {
+
+	if c <= 0 || len(sack)%c != 0 {
+		return nil, false
+	}
+	size := len(sack) / c
+	counts = make(map[rune][]int)
+	for i, r := range sack {
+		if counts[r] == nil {
+			counts[r] = make([]int, c)
+		}
+		counts[r][i/size] += 1
+	}
+	return counts, true
+}
+
+// Swap of two items between the compartments of a sack.
+type Swap struct {
+	item1 rune
+	from  int // Compartment of 'item1', starting at 1
+	item2 rune
+	to    int // Compartment of 'item2', starting at 1
+}
+
+// Computes the swaps between the 'c' compartments of a sack so that no item
+// type appears in more than one, keeping their sizes equal. Each item type is
+// assigned a compartment by dynamic programming over the sizes filled so far,
+// keeping in place as many items as possible; it fails if no assignment fills
+// every compartment exactly, e.g. when a type has more items than fit in one.
+func (alphabet *Alphabet) Reorganize(sack []rune, c int) (swaps []Swap, ok bool) {
+
+	counts, ok := CountItems(sack, c)
+	if !ok {
+		return
+	}
+	size := len(sack) / c
+	types := alphabet.Items(alphabet.Set(sack))
+
+	// Best assignment of the first types for each size of the compartments
+	type state struct {
+		sums []int  // Items in each compartment
+		kept int    // Items left in place
+		prev string // State before assigning the last type
+		to   int    // Compartment of the last type
+	}
+	layers := []map[string]state{{fmt.Sprint(make([]int, c)): {sums: make([]int, c)}}}
+	for _, r := range types {
+		total := 0
+		for _, n := range counts[r] {
+			total += n
+		}
+		next := make(map[string]state)
+		for k, st := range layers[len(layers)-1] {
+			for to := 0; to < c; to++ {
+				if st.sums[to]+total > size {
+					continue
+				}
+				sums := append([]int(nil), st.sums...)
+				sums[to] += total
+				key := fmt.Sprint(sums)
+				if old, seen := next[key]; !seen || st.kept+counts[r][to] > old.kept {
+					next[key] = state{sums: sums, kept: st.kept + counts[r][to], prev: k, to: to}
+				}
+			}
+		}
+		layers = append(layers, next)
+	}
+	full := make([]int, c)
+	for i := range full {
+		full[i] = size
+	}
+	key := fmt.Sprint(full)
+	if _, found := layers[len(layers)-1][key]; !found {
+		return nil, false
+	}
+	assign := make(map[rune]int)
+	for k := len(types); k > 0; k-- {
+		st := layers[k][key]
+		assign[types[k-1]] = st.to
+		key = st.prev
+	}
+
+	// Items out of their compartment, paired into swaps
+	type transfer struct {
+		item rune
+		from int
+		to   int
+	}
+	var pending []transfer
+	for _, r := range types {
+		for i, n := range counts[r] {
+			for ; i != assign[r] && n > 0; n-- {
+				pending = append(pending, transfer{item: r, from: i, to: assign[r]})
+			}
+		}
+	}
+	for len(pending) > 0 {
+		a := pending[0]
+		pending = pending[1:]
+		// Every compartment sends as many items as it receives: prefer an item
+		// going straight back, or else any item leaving the target compartment
+		k := -1
+		for j, b := range pending {
+			if b.from == a.to && (k < 0 || b.to == a.from) {
+				k = j
+			}
+		}
+		b := pending[k]
+		swaps = append(swaps, Swap{item1: a.item, from: a.from + 1, item2: b.item, to: b.from + 1})
+		if b.to == a.from {
+			pending = append(pending[:k], pending[k+1:]...)
+		} else {
+			pending[k].from = a.from
+		}
+	}
+	return swaps, true
+}
+
+// Suggests the badge to keep in a group: among the item types carried by all
+// its Elves, the one that would take the most items to remove. The other
+// candidates are removed from the Elf carrying the fewest of them.
+func (alphabet *Alphabet) SuggestBadge(sacks [][]rune) (badge rune, removals []Move, ok bool) {
+
+	common := alphabet.All()
+	for _, sack := range sacks {
+		common = common.And(alphabet.Set(sack))
+	}
+	candidates := alphabet.Items(common)
+	if len(candidates) == 0 {
+		return 0, nil, false
+	}
+
+	// Cheapest removal of each candidate: all its items in a single sack
+	cost := make([]int, len(candidates))
+	elf := make([]int, len(candidates))
+	for i, r := range candidates {
+		cost[i] = -1
+		for j, sack := range sacks {
+			n := 0
+			for _, item := range sack {
+				if item == r {
+					n += 1
+				}
+			}
+			if cost[i] < 0 || n < cost[i] {
+				cost[i] = n
+				elf[i] = j
+			}
+		}
+	}
+	keep := 0
+	for i := range candidates {
+		if cost[i] > cost[keep] {
+			keep = i
+		}
+	}
+	for i, r := range candidates {
+		if i != keep {
+			// 'from' is the Elf in the group and 'to' is 0 (out of the sack)
+			removals = append(removals, Move{item: r, count: cost[i], from: elf[i] + 1, to: 0})
+		}
+	}
+	return candidates[keep], removals, true
+}
+
+// Plans the reorganization of each rucksack and the badge to keep in each
+// group, printing the moves.
+func (scan *SackScanner) Plan() {
+
+	group := make([][]rune, 0, scan.group)
+	for sack := range scan.sack {
+		scan.num += 1
+		swaps, ok := scan.alphabet.Reorganize(sack.items, scan.compartments)
+		if len(sack.items)%scan.compartments != 0 {
+			fmt.Printf("Sack %d can't be split into %d compartments\n", scan.num, scan.compartments)
+		} else if !ok {
+			fmt.Printf("Sack %d can't be organized into %d equal compartments\n", scan.num, scan.compartments)
+		} else if len(swaps) == 0 {
+			fmt.Printf("Sack %d is already organized\n", scan.num)
+		}
+		for _, s := range swaps {
+			fmt.Printf("Sack %d: swap %c (priority %d) in compartment %d with %c (priority %d) in compartment %d\n",
+				scan.num, s.item1, scan.alphabet.Priority(s.item1), s.from, s.item2, scan.alphabet.Priority(s.item2), s.to)
+		}
+		scan.swaps += len(swaps)
+
+		group = append(group, sack.items)
+		if len(group) == scan.group {
+			first := scan.num - scan.group + 1
+			badge, removals, ok := scan.alphabet.SuggestBadge(group)
+			if !ok {
+				fmt.Printf("Group of sacks %d-%d has no badge\n", first, scan.num)
+			} else {
+				fmt.Printf("Group of sacks %d-%d keeps badge %c (priority %d)\n", first, scan.num, badge, scan.alphabet.Priority(badge))
+			}
+			for _, m := range removals {
+				fmt.Printf("Group of sacks %d-%d: remove %d item(s) %c from sack %d\n", first, scan.num, m.count, m.item, first+m.from-1)
+			}
+			group = group[:0]
+		}
+	}
+	fmt.Printf("Reorganizing the sacks takes %d swaps.\n", scan.swaps)
+}
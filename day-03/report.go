@@ -0,0 +1,180 @@
+// Miguel Nobre Castro
+// https://adventofcode.com/2022/day/3
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+)
+
+// Report of a single rucksack.
+type SackReport struct {
+	Line         int      `json:"line"`
+	Duplicated   []string `json:"duplicated"`   // Item types in all compartments
+	Compartments []int    `json:"compartments"` // Size of each compartment
+	Items        []string `json:"items"`        // All item types carried
+}
+
+// Report of a group of rucksacks without a single badge.
+type GroupReport struct {
+	First  int      `json:"first"` // Line of the first sack of the group
+	Last   int      `json:"last"`  // Line of the last sack of the group
+	Badges []string `json:"badges"`
+	Status string   `json:"status"` // "missing", "ambiguous" or "incomplete"
+}
+
+// Number of items of a type.
+type ItemCount struct {
+	Item     string `json:"item"`
+	Priority int    `json:"priority"`
+	Count    int    `json:"count"`
+}
+
+// Inventory Report of all the rucksacks.
+type Report struct {
+	Sacks     []SackReport  `json:"sacks"`
+	Frequency []ItemCount   `json:"frequency"` // Items of each type carried
+	Misplaced []ItemCount   `json:"misplaced"` // Sacks where each type is duplicated
+	Groups    []GroupReport `json:"groups"`
+}
+
+// Sorts counted items by decreasing count, then increasing priority.
+func (alphabet *Alphabet) counts(m map[rune]int) (counts []ItemCount) {
+
+	for r, n := range m {
+		counts = append(counts, ItemCount{Item: string(r), Priority: alphabet.Priority(r), Count: n})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		if counts[i].Priority != counts[j].Priority {
+			return counts[i].Priority < counts[j].Priority
+		}
+		return counts[i].Item < counts[j].Item
+	})
+	return
+}
+
+// Strings of the item types.
+func strs(items []rune) (s []string) {
+
+	s = make([]string, 0, len(items))
+	for _, r := range items {
+		s = append(s, string(r))
+	}
+	return
+}
+
+// Builds the inventory Report of the rucksacks.
+func (scan *SackScanner) Report() (report *Report) {
+
+	report = &Report{
+		Sacks:     make([]SackReport, 0),
+		Frequency: make([]ItemCount, 0),
+		Misplaced: make([]ItemCount, 0),
+		Groups:    make([]GroupReport, 0),
+	}
+	frequency := make(map[rune]int)
+	misplaced := make(map[rune]int)
+	group := GroupReport{}
+	common := scan.alphabet.All()
+	member := 0
+	for sack := range scan.sack {
+		scan.num += 1
+
+		// Sack
+		sizes := make([]int, scan.compartments)
+		for i := range sizes {
+			sizes[i] = len(sack.items) / scan.compartments
+			if i < len(sack.items)%scan.compartments {
+				sizes[i] += 1
+			}
+		}
+		duplicated, _ := scan.alphabet.Compartments(sack.items, scan.compartments)
+		types := make([]rune, 0)
+		seen := make(map[rune]bool)
+		for _, r := range sack.items {
+			frequency[r] += 1
+			if !seen[r] {
+				seen[r] = true
+				types = append(types, r)
+			}
+		}
+		sort.Slice(types, func(i, j int) bool { return types[i] < types[j] })
+		for _, r := range scan.alphabet.Items(duplicated) {
+			misplaced[r] += 1
+		}
+		report.Sacks = append(report.Sacks, SackReport{
+			Line:         sack.line,
+			Duplicated:   strs(scan.alphabet.Items(duplicated)),
+			Compartments: sizes,
+			Items:        strs(types),
+		})
+
+		// Group
+		if member == 0 {
+			group.First = sack.line
+		}
+		group.Last = sack.line
+		common = common.And(scan.alphabet.Set(sack.items))
+		member += 1
+		if member == scan.group {
+			group.Badges = strs(scan.alphabet.Items(common))
+			if len(group.Badges) == 0 {
+				group.Status = "missing"
+			} else if len(group.Badges) > 1 {
+				group.Status = "ambiguous"
+			}
+			if group.Status != "" {
+				report.Groups = append(report.Groups, group)
+			}
+			group = GroupReport{}
+			common = scan.alphabet.All()
+			member = 0
+		}
+	}
+	if member > 0 {
+		group.Badges = strs(scan.alphabet.Items(common))
+		group.Status = "incomplete"
+		report.Groups = append(report.Groups, group)
+	}
+
+	report.Frequency = scan.alphabet.counts(frequency)
+	report.Misplaced = scan.alphabet.counts(misplaced)
+	return
+}
+
+// Prints the Report as text.
+func (report *Report) Print(w io.Writer) {
+
+	for _, s := range report.Sacks {
+		fmt.Fprintf(w, "Sack on line %d: compartments %v, duplicated %v, item types %v\n", s.Line, s.Compartments, s.Duplicated, s.Items)
+	}
+	fmt.Fprintf(w, "Item type frequency:\n")
+	for _, c := range report.Frequency {
+		fmt.Fprintf(w, "  %s (priority %d): %d items\n", c.Item, c.Priority, c.Count)
+	}
+	fmt.Fprintf(w, "Most common misplaced item types:\n")
+	for _, c := range report.Misplaced {
+		fmt.Fprintf(w, "  %s (priority %d): %d sacks\n", c.Item, c.Priority, c.Count)
+	}
+	if len(report.Groups) == 0 {
+		fmt.Fprintf(w, "Every group has a single badge.\n")
+	}
+	for _, g := range report.Groups {
+		fmt.Fprintf(w, "Group on lines %d-%d has a %s badge %v\n", g.First, g.Last, g.Status, g.Badges)
+	}
+	return
+}
+
+// Writes the Report as JSON.
+func (report *Report) JSON(w io.Writer) error {
+
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
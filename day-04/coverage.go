@@ -0,0 +1,179 @@
+// Miguel Nobre Castro
+// https://adventofcode.com/2022/day/4
+
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Assignment of sections to an Elf.
+type Assignment struct {
+	pair     int         // Pair (or group) number, starting at 1
+	elf      int         // Elf in the pair, starting at 1
+	sections IntervalSet // Assigned sections, in one or more ranges
+}
+
+func (a Assignment) String() string {
+
+	return fmt.Sprintf("pair %d elf %d (%s)", a.pair, a.elf, strings.Trim(a.sections.String(), "{}"))
+}
+
+// Reads all the assignments of the Reader, one per Elf.
+func (reader *Reader) Assignments() (assignments []Assignment) {
+
+	for line := range reader.pair {
+		reader.num += 1
+		group, err := ParseGroup(line)
+		if err != nil {
+			fmt.Printf("Skipping malformed group '%s' on line %d\n", line, reader.num)
+			continue
+		}
+		for i, set := range group.elves {
+			assignments = append(assignments, Assignment{pair: reader.num, elf: i + 1, sections: set})
+		}
+	}
+	return
+}
+
+// Redundancy of an Elf: its sections also assigned to other Elves.
+type Redundancy struct {
+	assignment Assignment
+	sections   int  // Number of sections also assigned to others
+	full       bool // All its sections are assigned to others
+}
+
+// Coverage of the sections by all the assignments.
+type Coverage struct {
+	span      Interval     // From the lowest to the highest assigned section
+	threshold int          // Sections covered by more Elves are crowded
+	uncovered IntervalSet  // Sections in the span not assigned to any Elf
+	crowded   IntervalSet  // Sections assigned to more than 'threshold' Elves
+	depth     int          // Maximum number of Elves assigned to a section
+	deepest   IntervalSet  // Sections assigned to 'depth' Elves
+	redundant []Redundancy // Elves with sections also assigned to others
+}
+
+// Sweeps the assignments in O(n log n) to find the Coverage of the sections.
+func Analyze(assignments []Assignment, threshold int) (cov *Coverage) {
+
+	cov = &Coverage{threshold: threshold}
+
+	// Events: +1 where an assignment starts, -1 right after it ends
+	type event struct {
+		x     int
+		delta int
+	}
+	events := make([]event, 0, 2*len(assignments))
+	for _, a := range assignments {
+		for _, in := range a.sections.Intervals() {
+			events = append(events, event{in.lo, 1}, event{in.hi + 1, -1})
+		}
+	}
+	if len(events) == 0 {
+		cov.span = NewInterval(0, -1)
+		return
+	}
+	sort.Slice(events, func(i, j int) bool { return events[i].x < events[j].x })
+	cov.span = NewInterval(events[0].x, events[len(events)-1].x-1)
+
+	// Sections assigned to at least two Elves
+	var shared IntervalSet
+	depth := 0
+	i := 0
+	for i < len(events) {
+		x := events[i].x
+		for i < len(events) && events[i].x == x {
+			depth += events[i].delta
+			i += 1
+		}
+		if i == len(events) {
+			break
+		}
+		segment := NewInterval(x, events[i].x-1)
+		if depth == 0 {
+			cov.uncovered.Add(segment)
+		}
+		if depth > threshold {
+			cov.crowded.Add(segment)
+		}
+		if depth >= 2 {
+			shared.Add(segment)
+		}
+		if depth > cov.depth {
+			cov.depth = depth
+			cov.deepest = NewIntervalSet(segment)
+		} else if depth == cov.depth && depth > 0 {
+			cov.deepest.Add(segment)
+		}
+	}
+
+	// Prefix sums of the shared sections to measure each Elf's redundancy
+	intervals := shared.Intervals()
+	prefix := make([]int, len(intervals)+1)
+	for k, in := range intervals {
+		prefix[k+1] = prefix[k] + in.Length()
+	}
+	below := func(x int) int { // Shared sections lower than 'x'
+		k := sort.Search(len(intervals), func(k int) bool { return intervals[k].hi >= x })
+		n := prefix[k]
+		if k < len(intervals) && intervals[k].lo < x {
+			n += x - intervals[k].lo
+		}
+		return n
+	}
+	for _, a := range assignments {
+		n := 0
+		for _, in := range a.sections.Intervals() {
+			n += below(in.hi+1) - below(in.lo)
+		}
+		if n > 0 {
+			cov.redundant = append(cov.redundant, Redundancy{
+				assignment: a,
+				sections:   n,
+				full:       n == a.sections.Length(),
+			})
+		}
+	}
+	return
+}
+
+// Prints the Coverage, listing up to 'limit' redundant Elves.
+func (cov *Coverage) Print(limit int) {
+
+	fmt.Printf("Assigned sections span %s.\n", cov.span)
+	fmt.Printf("%d sections are not assigned to any Elf: %s\n", cov.uncovered.Length(), head(cov.uncovered, limit))
+	fmt.Printf("%d sections are assigned to more than %d Elves: %s\n", cov.crowded.Length(), cov.threshold, head(cov.crowded, limit))
+	fmt.Printf("Up to %d Elves are assigned the same section, at %s\n", cov.depth, head(cov.deepest, limit))
+
+	full := 0
+	for _, r := range cov.redundant {
+		if r.full {
+			full += 1
+		}
+	}
+	fmt.Printf("%d Elves share sections with others, %d of them entirely.\n", len(cov.redundant), full)
+	for k, r := range cov.redundant {
+		if k == limit {
+			fmt.Printf("...\n")
+			break
+		}
+		if r.full {
+			fmt.Printf("Elf of %s is entirely covered by other Elves\n", r.assignment)
+		} else {
+			fmt.Printf("Elf of %s has %d sections covered by other Elves\n", r.assignment, r.sections)
+		}
+	}
+	return
+}
+
+// First 'limit' Intervals of an IntervalSet.
+func head(set IntervalSet, limit int) string {
+
+	if len(set.intervals) <= limit {
+		return set.String()
+	}
+	return fmt.Sprintf("%s ... (%d intervals)", IntervalSet{set.intervals[:limit]}, len(set.intervals))
+}
@@ -0,0 +1,288 @@
+// Miguel Nobre Castro
+// https://adventofcode.com/2022/day/4
+
+package main
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+)
+
+// Crew of Elves selected to cover a target span of sections.
+type Crew struct {
+	target   Interval
+	elves    []Assignment
+	sections int        // Total sections assigned to the selected Elves
+	gaps     []Interval // Target sections no Elf is assigned to
+}
+
+// Whether the Crew covers the whole target span.
+func (crew *Crew) Feasible() bool {
+
+	return len(crew.gaps) == 0
+}
+
+// Span of all the assignments.
+func Span(assignments []Assignment) (span Interval) {
+
+	span = NewInterval(0, -1)
+	for _, a := range assignments {
+		intervals := a.sections.Intervals()
+		if len(intervals) == 0 {
+			continue
+		}
+		lo, hi := intervals[0].lo, intervals[len(intervals)-1].hi
+		if span.Empty() {
+			span = NewInterval(lo, hi)
+		}
+		span.lo = min(span.lo, lo)
+		span.hi = max(span.hi, hi)
+	}
+	return
+}
+
+// Range of an Elf: one of the Intervals of an assignment.
+type Range struct {
+	elf int // Index of the assignment
+	in  Interval
+}
+
+// Ranges of the assignments overlapping the target span.
+func ranges(assignments []Assignment, target Interval) (found []Range) {
+
+	for i, a := range assignments {
+		for _, in := range a.sections.Intervals() {
+			if in.Overlaps(target) {
+				found = append(found, Range{elf: i, in: in})
+			}
+		}
+	}
+	return
+}
+
+// Adds an Elf to the Crew, with all its ranges.
+func (crew *Crew) add(a Assignment) {
+
+	crew.elves = append(crew.elves, a)
+	crew.sections += a.sections.Length()
+	return
+}
+
+// Selects the Elves covering the target span, picking at each step the range
+// reaching the furthest among those starting in the covered sections. The
+// other ranges of its Elf are covered too, so the next step starts at the
+// first section none of the selected Elves is assigned to. Target sections no
+// Elf is assigned to are reported as gaps. This is the minimum number of
+// Elves when every Elf has a single range.
+func MinimumCrew(assignments []Assignment, target Interval) (crew *Crew) {
+
+	crew = &Crew{target: target}
+	sorted := ranges(assignments, target)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].in.lo < sorted[j].in.lo })
+
+	var covered IntervalSet // Sections of the selected Elves
+	selected := make(map[int]bool)
+	next := target.lo // First section not covered yet
+	i := 0
+	for next <= target.hi {
+		best := -1
+		for i < len(sorted) && sorted[i].in.lo <= next {
+			if !selected[sorted[i].elf] && (best < 0 || sorted[i].in.hi > sorted[best].in.hi) {
+				best = i
+			}
+			i += 1
+		}
+		if best < 0 || sorted[best].in.hi < next {
+			// Nobody covers 'next': skip to the next assignment or covered section
+			end := target.hi
+			if i < len(sorted) {
+				end = min(end, sorted[i].in.lo-1)
+			}
+			if gaps := covered.Gaps(NewInterval(next, end)); len(gaps) > 0 {
+				end = gaps[0].hi
+			}
+			crew.gaps = append(crew.gaps, NewInterval(next, end))
+			next = end + 1
+		} else {
+			selected[sorted[best].elf] = true
+			a := assignments[sorted[best].elf]
+			crew.add(a)
+			for _, in := range a.sections.Intervals() {
+				covered.Add(in)
+			}
+		}
+		next = covered.Reach(next) + 1
+	}
+	return
+}
+
+// Selects the Elves covering the target span with the fewest assigned
+// sections, by dynamic programming over their ranges sorted by their last
+// section: cost[x] is the cheapest cover of target.lo..x, and a range a-b
+// extends any cover reaching a-1..b-1 up to b, paying all the sections of its
+// Elf. An Elf picked for several of its ranges is only counted once, and Elves
+// whose sections are all covered by the rest of the Crew are dropped, so the
+// cover is the cheapest when every Elf has a single range.
+func LeanestCrew(assignments []Assignment, target Interval) (crew *Crew) {
+
+	crew = MinimumCrew(assignments, target)
+	crew.elves = nil
+	crew.sections = 0
+	if !crew.Feasible() {
+		return
+	}
+
+	sorted := ranges(assignments, target)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].in.hi < sorted[j].in.hi })
+
+	// Leaves: 'target.lo-1' (nothing covered yet) and the clipped ends of the ranges
+	keys := []int{target.lo - 1}
+	for _, r := range sorted {
+		keys = append(keys, min(r.in.hi, target.hi))
+	}
+	sort.Ints(keys)
+	keys = slices.Compact(keys)
+
+	tree := NewMinTree(len(keys))
+	last := make([]int, len(keys))   // Last range of the cheapest cover of each leaf
+	prev := make([]int, len(sorted)) // Leaf extended by each range
+	for k := range last {
+		last[k] = -1
+	}
+	tree.Relax(0, 0)
+	for i, r := range sorted {
+		lo := sort.SearchInts(keys, max(r.in.lo, target.lo)-1)
+		end := sort.SearchInts(keys, min(r.in.hi, target.hi))
+		cost, leaf := tree.Min(lo, end)
+		if cost == math.MaxInt {
+			continue
+		}
+		if tree.Relax(end, cost+assignments[r.elf].sections.Length()) {
+			last[end] = i
+			prev[i] = leaf
+		}
+	}
+
+	// Walk back the cheapest cover of the whole target span
+	var picked []int
+	for k := len(keys) - 1; last[k] >= 0; k = prev[last[k]] {
+		if elf := sorted[last[k]].elf; !slices.Contains(picked, elf) {
+			picked = append([]int{elf}, picked...)
+		}
+	}
+
+	// Drop the Elves made redundant by the other ranges of the Crew, costliest
+	// first: those whose target sections are all assigned to other Elves too
+	sort.SliceStable(picked, func(i, j int) bool {
+		return assignments[picked[i]].sections.Length() > assignments[picked[j]].sections.Length()
+	})
+	span := NewIntervalSet(target)
+	for true {
+		members := make([]Assignment, len(picked))
+		for k, elf := range picked {
+			members[k] = assignments[elf]
+		}
+		crowded := Analyze(members, 1).crowded
+		k := slices.IndexFunc(members, func(a Assignment) bool {
+			return crowded.ContainsSet(a.sections.Intersection(span))
+		})
+		if k < 0 {
+			break
+		}
+		picked = slices.Delete(picked, k, k+1)
+	}
+	sort.Slice(picked, func(i, j int) bool {
+		return assignments[picked[i]].sections.Intervals()[0].lo < assignments[picked[j]].sections.Intervals()[0].lo
+	})
+	for _, elf := range picked {
+		crew.add(assignments[elf])
+	}
+	return
+}
+
+// Segment tree of the cheapest covers reaching each leaf.
+type MinTree struct {
+	size int
+	cost []int // Cheapest cost of each node
+	arg  []int // Leaf holding the cheapest cost of each node
+}
+
+// MinTree constructor with 'n' leaves of infinite cost.
+func NewMinTree(n int) (tree *MinTree) {
+
+	size := 1
+	for size < n {
+		size *= 2
+	}
+	tree = &MinTree{
+		size: size,
+		cost: make([]int, 2*size),
+		arg:  make([]int, 2*size),
+	}
+	for i := range tree.cost {
+		tree.cost[i] = math.MaxInt
+	}
+	for i := 0; i < size; i++ {
+		tree.arg[size+i] = i
+	}
+	for i := size - 1; i > 0; i-- {
+		tree.arg[i] = tree.arg[2*i]
+	}
+	return
+}
+
+// Lowers the cost of a leaf, if 'cost' is cheaper.
+func (tree *MinTree) Relax(leaf int, cost int) bool {
+
+	i := tree.size + leaf
+	if cost >= tree.cost[i] {
+		return false
+	}
+	tree.cost[i] = cost
+	for i /= 2; i > 0; i /= 2 {
+		l, r := 2*i, 2*i+1
+		if tree.cost[l] <= tree.cost[r] {
+			tree.cost[i], tree.arg[i] = tree.cost[l], tree.arg[l]
+		} else {
+			tree.cost[i], tree.arg[i] = tree.cost[r], tree.arg[r]
+		}
+	}
+	return true
+}
+
+// Cheapest cost among the leaves lo..hi-1 and its leaf.
+func (tree *MinTree) Min(lo int, hi int) (cost int, leaf int) {
+
+	cost, leaf = math.MaxInt, -1
+	for l, r := lo+tree.size, hi+tree.size; l < r; l, r = l/2, r/2 {
+		if l%2 == 1 {
+			if tree.cost[l] < cost {
+				cost, leaf = tree.cost[l], tree.arg[l]
+			}
+			l += 1
+		}
+		if r%2 == 1 {
+			r -= 1
+			if tree.cost[r] < cost {
+				cost, leaf = tree.cost[r], tree.arg[r]
+			}
+		}
+	}
+	return
+}
+
+// Prints the Crew.
+func (crew *Crew) Print(title string) {
+
+	if !crew.Feasible() {
+		fmt.Printf("%s: sections %s can't be covered, no Elf is assigned to %v\n", title, crew.target, crew.gaps)
+		return
+	}
+	fmt.Printf("%s: %d Elves cover sections %s with %d assigned sections\n", title, len(crew.elves), crew.target, crew.sections)
+	for _, a := range crew.elves {
+		fmt.Printf("  Elf of %s\n", a)
+	}
+	return
+}
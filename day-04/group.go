@@ -0,0 +1,85 @@
+// Miguel Nobre Castro
+// https://adventofcode.com/2022/day/4
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Group of Elves assigned together on a line, each Elf to one or more ranges.
+//
+// Line example, three Elves where the first one has two ranges:
+// "2-4;7-9,3-5,1-1"
+type Group struct {
+	elves []IntervalSet
+}
+
+// Relation between two Elves of a Group.
+type Relation struct {
+	a         int  // First Elf, starting at 1
+	b         int  // Second Elf, starting at 1
+	contained bool // One of them has all the sections of the other
+	overlaps  bool // They share at least one section
+}
+
+// Parses the Elves of a Group and their ranges.
+func ParseGroup(line string) (group Group, err error) {
+
+	if strings.TrimSpace(line) == "" {
+		return group, errors.New("Group: empty line.")
+	}
+	for _, elf := range strings.Split(line, ",") {
+		var set IntervalSet
+		for _, s := range strings.Split(elf, ";") {
+			in, err := ParseInterval(s)
+			if err != nil {
+				return group, err
+			}
+			set.Add(in)
+		}
+		group.elves = append(group.elves, set)
+	}
+	return
+}
+
+// Relations between every pair of Elves in the Group.
+func (group Group) Relations() (relations []Relation) {
+
+	for i := 0; i < len(group.elves); i++ {
+		for j := i + 1; j < len(group.elves); j++ {
+			a, b := group.elves[i], group.elves[j]
+			relations = append(relations, Relation{
+				a:         i + 1,
+				b:         j + 1,
+				contained: a.ContainsSet(b) || b.ContainsSet(a),
+				overlaps:  a.Overlaps(b),
+			})
+		}
+	}
+	return
+}
+
+// Verdict of the Group: whether any of its pairs is fully contained or
+// overlaps. For a pair of Elves, these are the pair's own relations.
+func (group Group) Verdict() (contained bool, overlaps bool) {
+
+	for _, rel := range group.Relations() {
+		contained = contained || rel.contained
+		overlaps = overlaps || rel.overlaps
+	}
+	return
+}
+
+func (rel Relation) String() string {
+
+	switch {
+	case rel.contained:
+		return fmt.Sprintf("Elves %d and %d: one contains the other", rel.a, rel.b)
+	case rel.overlaps:
+		return fmt.Sprintf("Elves %d and %d: overlap", rel.a, rel.b)
+	}
+	return fmt.Sprintf("Elves %d and %d: disjoint", rel.a, rel.b)
+}
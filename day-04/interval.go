@@ -0,0 +1,269 @@
+// Miguel Nobre Castro
+// https://adventofcode.com/2022/day/4
+
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Interval of sections [lo, hi], both included. It's empty if hi < lo.
+type Interval struct {
+	lo int
+	hi int
+}
+
+// Interval constructor.
+func NewInterval(lo int, hi int) (in Interval) {
+
+	in = Interval{lo: lo, hi: hi}
+	return
+}
+
+// Parses an Interval from its "lo-hi" notation.
+func ParseInterval(s string) (in Interval, err error) {
+
+	bounds := strings.Split(strings.TrimSpace(s), "-")
+	if len(bounds) != 2 {
+		return in, fmt.Errorf("Interval: '%s' is not a 'lo-hi' range.", s)
+	}
+	lo, err := strconv.Atoi(bounds[0])
+	if err != nil {
+		return in, fmt.Errorf("Interval: invalid bound '%s'.", bounds[0])
+	}
+	hi, err := strconv.Atoi(bounds[1])
+	if err != nil {
+		return in, fmt.Errorf("Interval: invalid bound '%s'.", bounds[1])
+	}
+	return NewInterval(lo, hi), nil
+}
+
+// Whether the Interval holds no section.
+func (in Interval) Empty() bool {
+
+	return in.hi < in.lo
+}
+
+// Number of sections in the Interval.
+func (in Interval) Length() int {
+
+	if in.Empty() {
+		return 0
+	}
+	return in.hi - in.lo + 1
+}
+
+// Whether the Interval holds the section 'x'.
+func (in Interval) Has(x int) bool {
+
+	return in.lo <= x && x <= in.hi
+}
+
+// Whether the Interval fully contains 'other'. An empty Interval is contained
+// in any Interval.
+func (in Interval) Contains(other Interval) bool {
+
+	if other.Empty() {
+		return true
+	}
+	return in.lo <= other.lo && other.hi <= in.hi
+}
+
+// Whether both Intervals share at least one section. Touching Intervals, such
+// as 2-4 and 5-6, don't overlap.
+func (in Interval) Overlaps(other Interval) bool {
+
+	if in.Empty() || other.Empty() {
+		return false
+	}
+	return in.lo <= other.hi && other.lo <= in.hi
+}
+
+// Whether both Intervals overlap or touch, so that their union is an Interval.
+func (in Interval) Adjacent(other Interval) bool {
+
+	if in.Empty() || other.Empty() {
+		return false
+	}
+	return in.lo <= other.hi+1 && other.lo <= in.hi+1
+}
+
+// Sections in both Intervals.
+func (in Interval) Intersection(other Interval) Interval {
+
+	return NewInterval(max(in.lo, other.lo), min(in.hi, other.hi))
+}
+
+// Sections in any of both Intervals.
+func (in Interval) Union(other Interval) (set IntervalSet) {
+
+	set.Add(in)
+	set.Add(other)
+	return
+}
+
+func (in Interval) String() string {
+
+	if in.Empty() {
+		return "empty"
+	}
+	return fmt.Sprintf("%d-%d", in.lo, in.hi)
+}
+
+// Set of sections kept as sorted Intervals that neither overlap nor touch.
+type IntervalSet struct {
+	intervals []Interval
+}
+
+// IntervalSet constructor from any Intervals.
+func NewIntervalSet(intervals ...Interval) (set IntervalSet) {
+
+	for _, in := range intervals {
+		set.Add(in)
+	}
+	return
+}
+
+// Adds the sections of an Interval to the IntervalSet, merging the Intervals
+// it overlaps or touches.
+func (set *IntervalSet) Add(in Interval) {
+
+	if in.Empty() {
+		return
+	}
+	// First Interval that isn't entirely before 'in'
+	i := sort.Search(len(set.intervals), func(k int) bool {
+		return set.intervals[k].hi+1 >= in.lo
+	})
+	j := i
+	for j < len(set.intervals) && set.intervals[j].Adjacent(in) {
+		in.lo = min(in.lo, set.intervals[j].lo)
+		in.hi = max(in.hi, set.intervals[j].hi)
+		j += 1
+	}
+	if i == j {
+		set.intervals = slices.Insert(set.intervals, i, in)
+	} else {
+		set.intervals[i] = in
+		set.intervals = slices.Delete(set.intervals, i+1, j)
+	}
+	return
+}
+
+// Intervals of the IntervalSet in increasing order.
+func (set IntervalSet) Intervals() []Interval {
+
+	return set.intervals
+}
+
+// Number of sections in the IntervalSet.
+func (set IntervalSet) Length() (n int) {
+
+	for _, in := range set.intervals {
+		n += in.Length()
+	}
+	return
+}
+
+// Whether the IntervalSet holds the section 'x'.
+func (set IntervalSet) Has(x int) bool {
+
+	i := sort.Search(len(set.intervals), func(k int) bool { return set.intervals[k].hi >= x })
+	return i < len(set.intervals) && set.intervals[i].Has(x)
+}
+
+// Last section of the run of sections from 'x' held by the IntervalSet, or
+// x-1 if it doesn't hold 'x'.
+func (set IntervalSet) Reach(x int) int {
+
+	i := sort.Search(len(set.intervals), func(k int) bool { return set.intervals[k].hi >= x })
+	if i < len(set.intervals) && set.intervals[i].Has(x) {
+		return set.intervals[i].hi
+	}
+	return x - 1
+}
+
+// Whether the IntervalSet holds all the sections of an Interval.
+func (set IntervalSet) Contains(in Interval) bool {
+
+	if in.Empty() {
+		return true
+	}
+	i := sort.Search(len(set.intervals), func(k int) bool { return set.intervals[k].hi >= in.lo })
+	return i < len(set.intervals) && set.intervals[i].Contains(in)
+}
+
+// Whether the IntervalSet holds all the sections of 'other'.
+func (set IntervalSet) ContainsSet(other IntervalSet) bool {
+
+	return set.Intersection(other).Length() == other.Length()
+}
+
+// Whether both IntervalSets share at least one section.
+func (set IntervalSet) Overlaps(other IntervalSet) bool {
+
+	return len(set.Intersection(other).intervals) > 0
+}
+
+// Sections in any of both IntervalSets.
+func (set IntervalSet) Union(other IntervalSet) (out IntervalSet) {
+
+	out = NewIntervalSet(set.intervals...)
+	for _, in := range other.intervals {
+		out.Add(in)
+	}
+	return
+}
+
+// Sections in both IntervalSets.
+func (set IntervalSet) Intersection(other IntervalSet) (out IntervalSet) {
+
+	i, j := 0, 0
+	for i < len(set.intervals) && j < len(other.intervals) {
+		a, b := set.intervals[i], other.intervals[j]
+		if in := a.Intersection(b); !in.Empty() {
+			out.intervals = append(out.intervals, in)
+		}
+		if a.hi < b.hi {
+			i += 1
+		} else {
+			j += 1
+		}
+	}
+	return
+}
+
+// Sections of the Interval 'in' missing from the IntervalSet.
+func (set IntervalSet) Gaps(in Interval) (gaps []Interval) {
+
+	next := in.lo
+	for _, s := range set.intervals {
+		if s.hi < next {
+			continue
+		}
+		if s.lo > in.hi {
+			break
+		}
+		if s.lo > next {
+			gaps = append(gaps, NewInterval(next, s.lo-1))
+		}
+		next = s.hi + 1
+	}
+	if next <= in.hi {
+		gaps = append(gaps, NewInterval(next, in.hi))
+	}
+	return
+}
+
+func (set IntervalSet) String() string {
+
+	s := make([]string, len(set.intervals))
+	for i, in := range set.intervals {
+		s[i] = in.String()
+	}
+	return "{" + strings.Join(s, ", ") + "}"
+}
@@ -0,0 +1,126 @@
+// Miguel Nobre Castro
+// https://adventofcode.com/2022/day/4
+
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestIntervalOverlapsAdjacent(t *testing.T) {
+
+	tests := []struct {
+		a, b     Interval
+		overlaps bool
+		adjacent bool
+	}{
+		{NewInterval(2, 4), NewInterval(5, 6), false, true},  // Touching
+		{NewInterval(5, 6), NewInterval(2, 4), false, true},  // Touching, reversed
+		{NewInterval(2, 4), NewInterval(4, 6), true, true},   // Sharing one section
+		{NewInterval(2, 4), NewInterval(6, 8), false, false}, // Apart
+		{NewInterval(2, 8), NewInterval(3, 7), true, true},   // Contained
+		{NewInterval(6, 6), NewInterval(4, 6), true, true},   // Single section
+		{NewInterval(5, 4), NewInterval(2, 8), false, false}, // Empty
+		{NewInterval(2, 8), NewInterval(5, 4), false, false}, // Empty, reversed
+		{NewInterval(5, 4), NewInterval(5, 4), false, false}, // Both empty
+	}
+	for _, test := range tests {
+		if got := test.a.Overlaps(test.b); got != test.overlaps {
+			t.Errorf("%s.Overlaps(%s) = %t, want %t", test.a, test.b, got, test.overlaps)
+		}
+		if got := test.a.Adjacent(test.b); got != test.adjacent {
+			t.Errorf("%s.Adjacent(%s) = %t, want %t", test.a, test.b, got, test.adjacent)
+		}
+	}
+}
+
+func TestIntervalEmpty(t *testing.T) {
+
+	tests := []struct {
+		in     Interval
+		empty  bool
+		length int
+	}{
+		{NewInterval(2, 4), false, 3},
+		{NewInterval(4, 4), false, 1},
+		{NewInterval(5, 4), true, 0},
+		{NewInterval(9, 1), true, 0},
+	}
+	for _, test := range tests {
+		if got := test.in.Empty(); got != test.empty {
+			t.Errorf("%v.Empty() = %t, want %t", test.in, got, test.empty)
+		}
+		if got := test.in.Length(); got != test.length {
+			t.Errorf("%v.Length() = %d, want %d", test.in, got, test.length)
+		}
+	}
+}
+
+func TestIntervalSetAdd(t *testing.T) {
+
+	tests := []struct {
+		name string
+		add  []Interval
+		want []Interval
+	}{
+		{"disjoint", []Interval{{6, 8}, {2, 4}}, []Interval{{2, 4}, {6, 8}}},
+		{"touching", []Interval{{2, 4}, {5, 6}}, []Interval{{2, 6}}},
+		{"overlapping", []Interval{{2, 5}, {4, 8}}, []Interval{{2, 8}}},
+		{"bridging", []Interval{{1, 2}, {6, 7}, {10, 12}, {3, 9}}, []Interval{{1, 12}}},
+		{"contained", []Interval{{2, 8}, {3, 7}}, []Interval{{2, 8}}},
+		{"empty", []Interval{{2, 4}, {7, 6}}, []Interval{{2, 4}}},
+		{"only empty", []Interval{{7, 6}}, nil},
+	}
+	for _, test := range tests {
+		got := NewIntervalSet(test.add...).Intervals()
+		if !slices.Equal(got, test.want) {
+			t.Errorf("%s: NewIntervalSet(%v) = %v, want %v", test.name, test.add, got, test.want)
+		}
+	}
+}
+
+func TestIntervalSetIntersection(t *testing.T) {
+
+	tests := []struct {
+		a, b []Interval
+		want []Interval
+	}{
+		{[]Interval{{2, 4}}, []Interval{{5, 6}}, nil},
+		{[]Interval{{2, 4}}, []Interval{{4, 6}}, []Interval{{4, 4}}},
+		{[]Interval{{1, 3}, {6, 9}}, []Interval{{2, 7}}, []Interval{{2, 3}, {6, 7}}},
+		{[]Interval{{1, 10}}, []Interval{{2, 3}, {5, 6}}, []Interval{{2, 3}, {5, 6}}},
+		{[]Interval{{1, 10}}, nil, nil},
+	}
+	for _, test := range tests {
+		a, b := NewIntervalSet(test.a...), NewIntervalSet(test.b...)
+		if got := a.Intersection(b).Intervals(); !slices.Equal(got, test.want) {
+			t.Errorf("%s.Intersection(%s) = %v, want %v", a, b, got, test.want)
+		}
+		if got := b.Intersection(a).Intervals(); !slices.Equal(got, test.want) {
+			t.Errorf("%s.Intersection(%s) = %v, want %v", b, a, got, test.want)
+		}
+	}
+}
+
+func TestIntervalSetGaps(t *testing.T) {
+
+	tests := []struct {
+		set  []Interval
+		in   Interval
+		want []Interval
+	}{
+		{nil, NewInterval(1, 5), []Interval{{1, 5}}},
+		{[]Interval{{1, 5}}, NewInterval(1, 5), nil},
+		{[]Interval{{2, 3}, {6, 7}}, NewInterval(1, 9), []Interval{{1, 1}, {4, 5}, {8, 9}}},
+		{[]Interval{{2, 4}, {5, 6}}, NewInterval(2, 6), nil}, // Touching ranges merge
+		{[]Interval{{0, 2}, {8, 12}}, NewInterval(3, 7), []Interval{{3, 7}}},
+		{[]Interval{{1, 5}}, NewInterval(5, 4), nil}, // Empty
+	}
+	for _, test := range tests {
+		set := NewIntervalSet(test.set...)
+		if got := set.Gaps(test.in); !slices.Equal(got, test.want) {
+			t.Errorf("%s.Gaps(%s) = %v, want %v", set, test.in, got, test.want)
+		}
+	}
+}
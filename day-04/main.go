@@ -6,11 +6,10 @@ package main
 import (
 	"bufio"
 	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"os"
-	"strconv"
-	"strings"
 )
 
 // Reader class.
@@ -61,36 +60,103 @@ func NewReader(filename string) (reader *Reader) {
 	return
 }
 
-// Finds fully overlapped (Part1) and total overlapped (Part2) assignment pairs.
-func (reader *Reader) FindOverlaps() {
+// Finds fully overlapped (Part1) and total overlapped (Part2) assignment
+// groups, usually pairs. With 'verbose', prints the relation of every pair of
+// Elves in each group.
+func (reader *Reader) FindOverlaps(verbose bool) {
 
-	for pair := range reader.pair {
-		split := strings.Split(pair, ",")
-		elf1 := strings.Split(split[0], "-")
-		elf2 := strings.Split(split[1], "-")
-		elf1_a, _ := strconv.Atoi(elf1[0])
-		elf1_b, _ := strconv.Atoi(elf1[1])
-		elf2_a, _ := strconv.Atoi(elf2[0])
-		elf2_b, _ := strconv.Atoi(elf2[1])
+	for line := range reader.pair {
+		group, err := ParseGroup(line)
+		if err != nil {
+			fmt.Printf("Skipping malformed group '%s': %s\n", line, err)
+			continue
+		}
+		reader.num += 1
 
-		if (elf1_a <= elf2_a && elf2_a <= elf1_b) && (elf1_a <= elf2_b && elf2_b <= elf1_b) {
-			reader.contained += 1
-			reader.overlaps += 1
-		} else if (elf2_a <= elf1_a && elf1_a <= elf2_b) && (elf2_a <= elf1_b && elf1_b <= elf2_b) {
+		contained, overlaps := group.Verdict()
+		if contained {
 			reader.contained += 1
-			reader.overlaps += 1
-		} else if (elf1_a <= elf2_a && elf2_a <= elf1_b) || (elf1_a <= elf2_b && elf2_b <= elf1_b) || (elf2_a <= elf1_a && elf1_a <= elf2_b) || (elf2_a <= elf1_b && elf1_b <= elf2_b) {
+		}
+		if overlaps {
 			reader.overlaps += 1
 		}
-		reader.num += 1
+		if verbose {
+			fmt.Printf("Group %d '%s': contained=%t overlaps=%t\n", reader.num, line, contained, overlaps)
+			for _, rel := range group.Relations() {
+				fmt.Printf("  %s\n", rel)
+			}
+		}
 	}
 }
 
+// Analyzes the coverage of the sections by all the assignments.
+//
+// Usage: coverage [-n N] [-limit L]
+func coverage(filename string, args []string) {
+
+	cmd := flag.NewFlagSet("coverage", flag.ExitOnError)
+	threshold := cmd.Int("n", 1, "sections assigned to more than n Elves are reported as crowded")
+	limit := cmd.Int("limit", 20, "maximum number of items listed in the report")
+	cmd.Parse(args)
+
+	Analyze(NewReader(filename).Assignments(), *threshold).Print(*limit)
+	return
+}
+
+// Selects the Elves covering the target sections.
+//
+// Usage: crew [-target lo-hi]
+func crew(filename string, args []string) {
+
+	cmd := flag.NewFlagSet("crew", flag.ExitOnError)
+	target := cmd.String("target", "", "target sections 'lo-hi' of the crew (span of all the assignments by default)")
+	cmd.Parse(args)
+
+	assignments := NewReader(filename).Assignments()
+	span := Span(assignments)
+	if *target != "" {
+		var err error
+		if span, err = ParseInterval(*target); err != nil {
+			panic(err)
+		}
+	}
+	MinimumCrew(assignments, span).Print("Minimum crew")
+	LeanestCrew(assignments, span).Print("Leanest crew")
+	return
+}
+
+// Answers queries about the assignments read from the standard input.
+//
+// Usage: query [-limit L]
+func query(filename string, args []string) {
+
+	cmd := flag.NewFlagSet("query", flag.ExitOnError)
+	limit := cmd.Int("limit", 20, "maximum number of Elves or pairs listed per query")
+	cmd.Parse(args)
+
+	NewIntervalTree(NewReader(filename).Assignments()).Query(os.Stdin, os.Stdout, *limit)
+	return
+}
+
 func main() {
 
+	verbose := flag.Bool("v", false, "print the relations of the Elves in each group")
+	flag.Parse()
+
 	const filename string = "input.txt"
+	switch flag.Arg(0) {
+	case "coverage":
+		coverage(filename, flag.Args()[1:])
+		return
+	case "crew":
+		crew(filename, flag.Args()[1:])
+		return
+	case "query":
+		query(filename, flag.Args()[1:])
+		return
+	}
 	r := NewReader(filename)
-	r.FindOverlaps()
-	fmt.Printf("There are %d fully contained assignment pairs.\n", r.contained)
-	fmt.Printf("There are %d overlapped assignment pairs.\n", r.overlaps)
+	r.FindOverlaps(*verbose)
+	fmt.Printf("There are %d fully contained assignment groups.\n", r.contained)
+	fmt.Printf("There are %d overlapped assignment groups.\n", r.overlaps)
 }
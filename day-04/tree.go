@@ -0,0 +1,170 @@
+// Miguel Nobre Castro
+// https://adventofcode.com/2022/day/4
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Interval tree of assignments: a balanced binary search tree laid out over
+// the ranges of the Elves sorted by first section, where each node also keeps
+// the highest section assigned in its subtree.
+type IntervalTree struct {
+	assignments []Assignment
+	nodes       []Range // Sorted by first section, the root is in the middle
+	maxhi       []int   // Highest section in the subtree of each node
+}
+
+// IntervalTree constructor.
+func NewIntervalTree(assignments []Assignment) (tree *IntervalTree) {
+
+	var nodes []Range
+	for i, a := range assignments {
+		for _, in := range a.sections.Intervals() {
+			nodes = append(nodes, Range{elf: i, in: in})
+		}
+	}
+	sort.Slice(nodes, func(i, j int) bool { return nodes[i].in.lo < nodes[j].in.lo })
+	tree = &IntervalTree{
+		assignments: assignments,
+		nodes:       nodes,
+		maxhi:       make([]int, len(nodes)),
+	}
+	tree.build(0, len(nodes))
+	return
+}
+
+// Computes the highest section of the subtree over nodes lo..hi-1.
+func (tree *IntervalTree) build(lo int, hi int) int {
+
+	if lo >= hi {
+		return -1 << 62
+	}
+	mid := (lo + hi) / 2
+	tree.maxhi[mid] = max(tree.nodes[mid].in.hi, tree.build(lo, mid), tree.build(mid+1, hi))
+	return tree.maxhi[mid]
+}
+
+// Calls 'visit' once with every assignment overlapping 'in'.
+func (tree *IntervalTree) Overlapping(in Interval, visit func(Assignment)) {
+
+	if in.Empty() {
+		return
+	}
+	seen := make(map[int]bool) // Elves with several ranges overlapping 'in'
+	var walk func(lo int, hi int)
+	walk = func(lo int, hi int) {
+		if lo >= hi {
+			return
+		}
+		mid := (lo + hi) / 2
+		if tree.maxhi[mid] < in.lo {
+			// Nothing in this subtree reaches 'in'
+			return
+		}
+		walk(lo, mid)
+		if tree.nodes[mid].in.lo > in.hi {
+			// Neither this node nor its right subtree start before the end of 'in'
+			return
+		}
+		if elf := tree.nodes[mid].elf; tree.nodes[mid].in.Overlaps(in) && !seen[elf] {
+			seen[elf] = true
+			visit(tree.assignments[elf])
+		}
+		walk(mid+1, hi)
+	}
+	walk(0, len(tree.nodes))
+	return
+}
+
+// Assignments covering the section 'x'.
+func (tree *IntervalTree) Covering(x int) (found []Assignment) {
+
+	tree.Overlapping(NewInterval(x, x), func(a Assignment) { found = append(found, a) })
+	return
+}
+
+// Calls 'visit' with every pair of overlapping assignments across the camp,
+// each pair once.
+func (tree *IntervalTree) Pairs(visit func(Assignment, Assignment)) {
+
+	seen := make(map[[2]int]bool) // Elves overlapping on several ranges
+	for i, a := range tree.nodes {
+		// Later nodes start no earlier, so only those starting within 'a' overlap it
+		j := i + 1
+		for j < len(tree.nodes) && tree.nodes[j].in.lo <= a.in.hi {
+			pair := [2]int{min(a.elf, tree.nodes[j].elf), max(a.elf, tree.nodes[j].elf)}
+			if !seen[pair] {
+				seen[pair] = true
+				visit(tree.assignments[pair[0]], tree.assignments[pair[1]])
+			}
+			j += 1
+		}
+	}
+	return
+}
+
+// Answers the queries read from 'in', one per line:
+//
+//	cover S     Elves assigned the section S
+//	overlap a-b Elves assigned any section in a-b
+//	pairs       pairs of Elves sharing sections across the camp
+func (tree *IntervalTree) Query(in io.Reader, out io.Writer, limit int) {
+
+	list := func(found []Assignment) {
+		fmt.Fprintf(out, "%d Elves\n", len(found))
+		for k, a := range found {
+			if k == limit {
+				fmt.Fprintf(out, "...\n")
+				break
+			}
+			fmt.Fprintf(out, "  Elf of %s\n", a)
+		}
+	}
+
+	r := bufio.NewScanner(in)
+	for r.Scan() {
+		fields := strings.Fields(r.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		switch {
+		case fields[0] == "cover" && len(fields) == 2:
+			x, err := strconv.Atoi(fields[1])
+			if err != nil {
+				fmt.Fprintf(out, "Invalid section '%s'\n", fields[1])
+				continue
+			}
+			list(tree.Covering(x))
+		case fields[0] == "overlap" && len(fields) == 2:
+			span, err := ParseInterval(fields[1])
+			if err != nil {
+				fmt.Fprintln(out, err)
+				continue
+			}
+			var found []Assignment
+			tree.Overlapping(span, func(a Assignment) { found = append(found, a) })
+			list(found)
+		case fields[0] == "pairs" && len(fields) == 1:
+			n := 0
+			tree.Pairs(func(a Assignment, b Assignment) {
+				if n < limit {
+					fmt.Fprintf(out, "  Elf of %s overlaps Elf of %s\n", a, b)
+				} else if n == limit {
+					fmt.Fprintf(out, "...\n")
+				}
+				n += 1
+			})
+			fmt.Fprintf(out, "%d overlapping pairs\n", n)
+		default:
+			fmt.Fprintf(out, "Unknown query '%s' (cover S, overlap a-b or pairs)\n", r.Text())
+		}
+	}
+	return
+}
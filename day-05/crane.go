@@ -0,0 +1,114 @@
+// Miguel Nobre Castro
+// https://adventofcode.com/2022/day/5
+
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+)
+
+// Instruction of the crane: moves 'num' crates from stack 'from' to stack 'to'
+// (both starting at 0).
+type Instruction struct {
+	num  int
+	from int
+	to   int
+	line int // Line number in the input, if any
+}
+
+var instructionRe = regexp.MustCompile(`^move ([0-9]+) from ([0-9]+) to ([0-9]+)$`)
+
+// Parses an Instruction from its "move N from A to B" notation.
+func ParseInstruction(s string) (ins Instruction, err error) {
+
+	move := instructionRe.FindStringSubmatch(s)
+	if move == nil {
+		return ins, fmt.Errorf("Instruction: '%s' is not 'move N from A to B'.", s)
+	}
+	ins.num, _ = strconv.Atoi(move[1])
+	ins.from, _ = strconv.Atoi(move[2])
+	ins.to, _ = strconv.Atoi(move[3])
+	ins.from -= 1
+	ins.to -= 1
+	return
+}
+
+func (ins Instruction) String() string {
+
+	return fmt.Sprintf("move %d from %d to %d", ins.num, ins.from+1, ins.to+1)
+}
+
+// Crane executing the instructions on a Cargo.
+type Crane interface {
+	Name() string
+	// Moves the crates of an Instruction and returns the cost of operating.
+	Move(cargo *Cargo, ins Instruction) (cost int)
+}
+
+// CrateMover crane: lifts up to 'capacity' crates at once (0 for no limit),
+// keeping their order, and splits larger moves into several lifts.
+type CrateMover struct {
+	name     string
+	capacity int // Maximum number of crates per lift
+	perCrate int // Cost of moving a crate
+	perLift  int // Cost of a lift
+}
+
+// CrateMover constructor.
+func NewCrateMover(name string, capacity int, perCrate int, perLift int) (crane *CrateMover) {
+
+	crane = &CrateMover{
+		name:     name,
+		capacity: capacity,
+		perCrate: perCrate,
+		perLift:  perLift,
+	}
+	return
+}
+
+// CrateMover 9000: moves one crate at a time.
+func CrateMover9000() *CrateMover {
+
+	return NewCrateMover("CrateMover 9000", 1, 0, 1)
+}
+
+// Crane of a model: "9000", "9001" or "N" for a crane lifting up to N crates.
+func NewCrane(model string) (crane Crane, err error) {
+
+	switch model {
+	case "9000":
+		return CrateMover9000(), nil
+	case "9001":
+		return CrateMover9001(), nil
+	}
+	capacity, err := strconv.Atoi(model)
+	if err != nil || capacity <= 0 {
+		return nil, fmt.Errorf("Crane: unknown model '%s'.", model)
+	}
+	return NewCrateMover(fmt.Sprintf("CrateMover (capacity %d)", capacity), capacity, 0, 1), nil
+}
+
+// CrateMover 9001: moves any number of crates at once.
+func CrateMover9001() *CrateMover {
+
+	return NewCrateMover("CrateMover 9001", 0, 0, 1)
+}
+
+func (crane *CrateMover) Name() string { return crane.name }
+
+func (crane *CrateMover) Move(cargo *Cargo, ins Instruction) (cost int) {
+
+	left := ins.num
+	for left > 0 {
+		n := left
+		if crane.capacity > 0 && n > crane.capacity {
+			n = crane.capacity
+		}
+		cargo.stacks[ins.to].PushN(cargo.stacks[ins.from].PopN(n))
+		cost += crane.perLift + n*crane.perCrate
+		left -= n
+	}
+	return
+}
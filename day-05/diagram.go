@@ -0,0 +1,120 @@
+// Miguel Nobre Castro
+// https://adventofcode.com/2022/day/5
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Draws the Stacks in the input format, crates as '[A]' and the stack numbers
+// in the footer. Rows keep their trailing spaces, so every row is as wide as
+// the footer.
+func (cargo *Cargo) Diagram() string {
+
+	height := 0
+	crates := make([][]rune, len(cargo.stacks))
+	for i, stack := range cargo.stacks {
+		crates[i] = stack.Crates()
+		height = max(height, len(crates[i]))
+	}
+
+	var b strings.Builder
+	cells := make([]string, len(cargo.stacks))
+	for h := height - 1; h >= 0; h-- {
+		for i := range crates {
+			if h < len(crates[i]) {
+				cells[i] = fmt.Sprintf("[%c]", crates[i][h])
+			} else {
+				cells[i] = "   "
+			}
+		}
+		b.WriteString(strings.Join(cells, " ") + "\n")
+	}
+	for i := range cells {
+		cells[i] = fmt.Sprintf("%-3s", fmt.Sprintf("%2d", i+1))
+	}
+	b.WriteString(strings.Join(cells, " ") + "\n")
+	return b.String()
+}
+
+// Instructions loaded but not applied yet.
+func (cargo *Cargo) Remaining() []Instruction {
+
+	return cargo.program[len(cargo.applied):]
+}
+
+// Writes the Cargo as an input file: the diagram of the Stacks, a blank line
+// and the remaining instructions.
+func (cargo *Cargo) Save(w io.Writer) error {
+
+	out := bufio.NewWriter(w)
+	fmt.Fprintf(out, "%s\n", cargo.Diagram())
+	for _, ins := range cargo.Remaining() {
+		fmt.Fprintf(out, "%s\n", ins)
+	}
+	return out.Flush()
+}
+
+// Saves the Cargo to a file and parses it back, checking that both Cargos
+// hold the same Stacks and remaining instructions.
+func (cargo *Cargo) SaveFile(filename string) error {
+
+	f, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+	err = cargo.Save(f)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+
+	saved := NewCargo(filename)
+	saved.Load(cargo.crane)
+	if len(saved.stacks) != len(cargo.stacks) {
+		return fmt.Errorf("Diagram: saved %d stacks, parsed %d back.", len(cargo.stacks), len(saved.stacks))
+	}
+	for i := range cargo.stacks {
+		if !slices.Equal(saved.stacks[i].Crates(), cargo.stacks[i].Crates()) {
+			return fmt.Errorf("Diagram: stack %d saved as '%s', parsed back as '%s'.",
+				i+1, string(cargo.stacks[i].Crates()), string(saved.stacks[i].Crates()))
+		}
+	}
+	remaining := cargo.Remaining()
+	if len(saved.program) != len(remaining) {
+		return fmt.Errorf("Diagram: saved %d instructions, parsed %d back.", len(remaining), len(saved.program))
+	}
+	for i, ins := range saved.program {
+		if ins.num != remaining[i].num || ins.from != remaining[i].from || ins.to != remaining[i].to {
+			return fmt.Errorf("Diagram: instruction '%s' parsed back as '%s'.", remaining[i], ins)
+		}
+	}
+	return nil
+}
+
+// Animates the remaining moves on the terminal, redrawing the diagram after
+// each one.
+func (cargo *Cargo) Animate(out io.Writer, delay time.Duration) error {
+
+	const CLEAR string = "\033[H\033[2J"
+	fmt.Fprintf(out, "%s%s\n%s", CLEAR, cargo.crane.Name(), cargo.Diagram())
+	for len(cargo.Remaining()) > 0 {
+		time.Sleep(delay)
+		ins := cargo.Remaining()[0]
+		if err := cargo.Step(); err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "%s%s: %s (move %d of %d)\n%s", CLEAR, cargo.crane.Name(), ins, cargo.Position(), len(cargo.program), cargo.Diagram())
+	}
+	fmt.Fprintf(out, "Tops: %s\n", cargo.Tops())
+	return nil
+}
@@ -0,0 +1,105 @@
+// Miguel Nobre Castro
+// https://adventofcode.com/2022/day/5
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+const SAMPLE string = `    [D]    
+[N] [C]    
+[Z] [M] [P]
+ 1   2   3 
+
+move 1 from 2 to 1
+move 3 from 1 to 3
+move 2 from 2 to 1
+move 1 from 1 to 2
+`
+
+// Writes the input file of a test.
+func write(t *testing.T, name string, text string) string {
+
+	t.Helper()
+	filename := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(filename, []byte(text), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return filename
+}
+
+// Parses a saved Cargo back and compares it with the original one.
+func roundTrip(t *testing.T, cargo *Cargo) {
+
+	t.Helper()
+	filename := filepath.Join(t.TempDir(), "saved.txt")
+	if err := cargo.SaveFile(filename); err != nil {
+		t.Fatal(err)
+	}
+	saved := NewCargo(filename)
+	saved.Load(cargo.crane)
+	if got, want := saved.Arrangement(), cargo.Arrangement(); !slices.EqualFunc(got, want, slices.Equal) {
+		t.Errorf("Parsed back %q, saved %q", got, want)
+	}
+	remaining := cargo.Remaining()
+	if len(saved.program) != len(remaining) {
+		t.Fatalf("Parsed back %d instructions, saved %d", len(saved.program), len(remaining))
+	}
+	for i, ins := range saved.program {
+		if ins.String() != remaining[i].String() {
+			t.Errorf("Instruction %d parsed back as '%s', saved '%s'", i+1, ins, remaining[i])
+		}
+	}
+}
+
+func TestSaveRoundTrip(t *testing.T) {
+
+	input := write(t, "input.txt", SAMPLE)
+	for _, model := range []string{"9000", "9001", "2"} {
+		for seek := 0; seek <= 4; seek++ {
+			crane, err := NewCrane(model)
+			if err != nil {
+				t.Fatal(err)
+			}
+			cargo := NewCargo(input)
+			cargo.Load(crane)
+			if err := cargo.Seek(seek); err != nil {
+				t.Fatal(err)
+			}
+			t.Run(model+"/"+string(rune('0'+seek)), func(t *testing.T) { roundTrip(t, cargo) })
+		}
+	}
+}
+
+func TestSaveEmptyStacks(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		cargo [][]rune
+	}{
+		{"first stack empty", [][]rune{{}, []rune("MCD"), []rune("PZN")}},
+		{"last stacks empty", [][]rune{[]rune("ZN"), {}, {}}},
+		{"no crates", [][]rune{{}, {}, {}}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			cargo := arranged(test.cargo)
+			cargo.program = []Instruction{{num: 1, from: 0, to: 2}}
+			roundTrip(t, cargo)
+		})
+	}
+}
+
+func TestDiagram(t *testing.T) {
+
+	cargo := NewCargo(write(t, "input.txt", SAMPLE))
+	cargo.Load(CrateMover9000()) // Drains the instructions before the file is removed
+	want := "    [D]    \n[N] [C]    \n[Z] [M] [P]\n 1   2   3 \n"
+	if got := cargo.Diagram(); got != want {
+		t.Errorf("Diagram() = %q, want %q", got, want)
+	}
+}
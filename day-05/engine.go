@@ -0,0 +1,250 @@
+// Miguel Nobre Castro
+// https://adventofcode.com/2022/day/5
+
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+)
+
+// Instruction parsed once, or the reason it can't be executed.
+type parsed struct {
+	ins Instruction
+	err error
+}
+
+// Engine for large Cargos: the Stacks are byte slices and the instructions
+// are parsed once when loading, so a crane moves a block of crates with a
+// single copy instead of one linked Crate at a time.
+type Engine struct {
+	initial [][]byte // Crates of each Stack, from the bottom to the top
+	stacks  [][]byte
+	program []parsed
+	cost    int
+}
+
+// Parses a "move N from A to B" line without regular expressions. Returns
+// false if the line isn't exactly in that notation.
+func scanInstruction(s []byte) (ins Instruction, ok bool) {
+
+	pos := 0
+	word := func(w string) bool {
+		if len(s)-pos < len(w) || string(s[pos:pos+len(w)]) != w {
+			return false
+		}
+		pos += len(w)
+		return true
+	}
+	number := func() (n int) {
+		start := pos
+		for pos < len(s) && s[pos] >= '0' && s[pos] <= '9' && pos-start < 18 {
+			n = n*10 + int(s[pos]-'0')
+			pos += 1
+		}
+		if pos == start || (pos < len(s) && s[pos] >= '0' && s[pos] <= '9') {
+			return -1
+		}
+		return
+	}
+	if !word("move ") {
+		return
+	}
+	if ins.num = number(); ins.num < 0 || !word(" from ") {
+		return
+	}
+	if ins.from = number(); ins.from < 0 || !word(" to ") {
+		return
+	}
+	if ins.to = number(); ins.to < 0 || pos != len(s) {
+		return
+	}
+	ins.from -= 1
+	ins.to -= 1
+	return ins, true
+}
+
+// Engine constructor from an input file, read as NewCargo does.
+func NewEngine(filename string) (engine *Engine, err error) {
+
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	engine = &Engine{}
+	var rows [][]byte
+	diagram := true
+	footer := 0 // Stacks numbered in the footer
+	num := 0
+	r := bufio.NewScanner(f)
+	r.Buffer(make([]byte, 0, 1<<16), 1<<26)
+	for r.Scan() {
+		s := r.Bytes()
+		num += 1
+		if len(s) == 0 {
+			continue
+		}
+		if diagram {
+			letter := false
+			for _, c := range s {
+				if c >= 'A' && c <= 'Z' {
+					letter = true
+					break
+				}
+			}
+			if letter {
+				rows = append(rows, append([]byte(nil), s...))
+				continue
+			}
+			// Reached the footer and the instructions
+			diagram = false
+			if footerRe.Match(s) {
+				footer = len(bytes.Fields(s))
+				continue
+			}
+		}
+		ins, ok := scanInstruction(s)
+		if !ok {
+			ins, err = ParseInstruction(string(s))
+		}
+		if err != nil {
+			err = lineError(num, s, err)
+		}
+		ins.line = num
+		engine.program = append(engine.program, parsed{ins: ins, err: err})
+		err = nil
+	}
+	if err = r.Err(); err != nil {
+		return nil, err
+	}
+
+	size := footer
+	if len(rows) > 0 {
+		size = max(size, (len(rows[0])+1)/4)
+	}
+	engine.initial = make([][]byte, size)
+	for k := len(rows) - 1; k >= 0; k-- {
+		for i := 0; i < (len(rows[k])+1)/4 && i < len(engine.initial); i++ {
+			if c := rows[k][i*4+1]; c != ' ' {
+				engine.initial[i] = append(engine.initial[i], c)
+			}
+		}
+	}
+	return
+}
+
+// Checks that an Instruction can be executed on 'stacks' Stacks, where
+// 'held' is the number of crates of a Stack.
+func validate(ins Instruction, stacks int, held func(i int) int) error {
+
+	if ins.from < 0 || ins.from >= stacks {
+		return fmt.Errorf("unknown source stack %d", ins.from+1)
+	}
+	if ins.to < 0 || ins.to >= stacks {
+		return fmt.Errorf("unknown target stack %d", ins.to+1)
+	}
+	if ins.num <= 0 {
+		return fmt.Errorf("moving %d crates", ins.num)
+	}
+	if n := held(ins.from); n < ins.num {
+		return fmt.Errorf("stack %d underflows, it holds %d crates", ins.from+1, n)
+	}
+	return nil
+}
+
+// Checks that an Instruction can be executed on the Stacks.
+func (engine *Engine) Check(ins Instruction) error {
+
+	return validate(ins, len(engine.stacks), func(i int) int { return len(engine.stacks[i]) })
+}
+
+// Executes the instructions from the initial Stacks with a crane lifting up
+// to 'capacity' crates at once (0 for no limit), keeping their order, at a
+// cost of one per lift. Invalid instructions are handled as in Cargo.Run.
+func (engine *Engine) Run(capacity int, mode Mode) error {
+
+	engine.reset()
+	for _, p := range engine.program {
+		err := p.err
+		if err == nil {
+			if err = engine.Check(p.ins); err != nil {
+				err = lineError(p.ins.line, p.ins, err)
+			}
+		}
+		if err != nil {
+			if mode == Strict {
+				return err
+			}
+			fmt.Printf("Warning: %s Skipped.\n", err)
+			continue
+		}
+		engine.cost += engine.move(p.ins, capacity)
+	}
+	return nil
+}
+
+// Restores the initial Stacks.
+func (engine *Engine) reset() {
+
+	engine.stacks = make([][]byte, len(engine.initial))
+	for i, crates := range engine.initial {
+		engine.stacks[i] = append([]byte(nil), crates...)
+	}
+	engine.cost = 0
+	return
+}
+
+// Moves the crates of an Instruction, lift by lift, and returns the lifts.
+func (engine *Engine) move(ins Instruction, capacity int) (lifts int) {
+
+	from, to := engine.stacks[ins.from], engine.stacks[ins.to]
+	if capacity <= 0 || capacity > ins.num {
+		capacity = ins.num
+	}
+	lifts = (ins.num + capacity - 1) / capacity
+	if ins.from == ins.to {
+		// Every lift puts its crates back in place
+		return
+	}
+	if capacity == 1 {
+		for k := 1; k <= ins.num; k++ {
+			to = append(to, from[len(from)-k])
+		}
+		from = from[:len(from)-ins.num]
+	} else {
+		for left := ins.num; left > 0; left -= capacity {
+			n := min(left, capacity)
+			to = append(to, from[len(from)-n:]...)
+			from = from[:len(from)-n]
+		}
+	}
+	engine.stacks[ins.from], engine.stacks[ins.to] = from, to
+	return
+}
+
+// Copy of the crates of each Stack.
+func (engine *Engine) Snapshot() (stacks [][]byte) {
+
+	stacks = make([][]byte, len(engine.stacks))
+	for i, crates := range engine.stacks {
+		stacks[i] = slices.Clone(crates)
+	}
+	return
+}
+
+// Crates on top of each Stack.
+func (engine *Engine) Tops() string {
+
+	tops := make([]byte, 0, len(engine.stacks))
+	for _, crates := range engine.stacks {
+		if len(crates) > 0 {
+			tops = append(tops, crates[len(crates)-1])
+		}
+	}
+	return string(tops)
+}
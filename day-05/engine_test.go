@@ -0,0 +1,202 @@
+// Miguel Nobre Castro
+// https://adventofcode.com/2022/day/5
+
+package main
+
+import (
+	"bytes"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+// Size of the benchmark workload.
+const (
+	BENCH_STACKS int = 1000
+	BENCH_CRATES int = 100000
+	BENCH_MOVES  int = 1000000
+)
+
+// Random Cargo of 'crates' crates in 'stacks' Stacks, with 'moves' valid
+// instructions of up to 'most' crates each.
+func workload(stacks int, crates int, moves int, most int, seed int64) (cargo *Cargo) {
+
+	rng := rand.New(rand.NewSource(seed))
+	arrangement := make([][]rune, stacks)
+	for k := 0; k < crates; k++ {
+		i := rng.Intn(stacks)
+		arrangement[i] = append(arrangement[i], rune('A'+rng.Intn(26)))
+	}
+	cargo = arranged(arrangement)
+	heights := cargo.Counts()
+	for len(cargo.program) < moves && crates > 0 {
+		from, to := rng.Intn(stacks), rng.Intn(stacks)
+		if heights[from] == 0 || from == to {
+			continue
+		}
+		n := 1 + rng.Intn(min(heights[from], most))
+		heights[from] -= n
+		heights[to] += n
+		cargo.program = append(cargo.program, Instruction{num: n, from: from, to: to})
+	}
+	return
+}
+
+// Saves a workload as an input file.
+func saveTemp(tb testing.TB, cargo *Cargo) string {
+
+	tb.Helper()
+	filename := filepath.Join(tb.TempDir(), "workload.txt")
+	f, err := os.Create(filename)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	defer f.Close()
+	if err := cargo.Save(f); err != nil {
+		tb.Fatal(err)
+	}
+	return filename
+}
+
+// Linked-list Cargo of a workload, reading its program as input lines.
+func linked(cargo *Cargo) *Cargo {
+
+	copy := arranged(cargo.Arrangement())
+	instructions := make(chan Line, 1)
+	go func() {
+		for i, ins := range cargo.program {
+			instructions <- Line{num: i + 1, text: ins.String()}
+		}
+		close(instructions)
+	}()
+	copy.instructions = instructions
+	return copy
+}
+
+func TestEngineMatchesCargo(t *testing.T) {
+
+	w := workload(50, 2000, 20000, 20, 1)
+	engine, err := NewEngine(saveTemp(t, w))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, capacity := range []int{1, 0, 5} {
+		cargo := linked(w)
+		if err := cargo.Run(NewCrateMover("CrateMover", capacity, 0, 1), Strict); err != nil {
+			t.Fatal(err)
+		}
+		if err := engine.Run(capacity, Strict); err != nil {
+			t.Fatal(err)
+		}
+		want := cargo.Arrangement()
+		for i, crates := range engine.stacks {
+			if !slices.Equal([]rune(string(crates)), want[i]) {
+				t.Fatalf("Capacity %d: stack %d is '%s', the Cargo has '%s'", capacity, i+1, crates, string(want[i]))
+			}
+		}
+		if engine.cost != cargo.cost {
+			t.Errorf("Capacity %d: cost %d, the Cargo's %d", capacity, engine.cost, cargo.cost)
+		}
+	}
+}
+
+func TestParallelMatchesRun(t *testing.T) {
+
+	engine, err := NewEngine(saveTemp(t, workload(50, 2000, 20000, 20, 2)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, capacity := range []int{1, 0, 5} {
+		if err := engine.Run(capacity, Strict); err != nil {
+			t.Fatal(err)
+		}
+		stacks, cost := engine.Snapshot(), engine.cost
+		if err := engine.RunParallel(capacity, 4, Strict); err != nil {
+			t.Fatal(err)
+		}
+		for i := range stacks {
+			if !bytes.Equal(engine.stacks[i], stacks[i]) {
+				t.Fatalf("Capacity %d: stack %d is '%s', the sequential run has '%s'", capacity, i+1, engine.stacks[i], stacks[i])
+			}
+		}
+		if engine.cost != cost {
+			t.Errorf("Capacity %d: cost %d, the sequential run's %d", capacity, engine.cost, cost)
+		}
+	}
+}
+
+func TestSchedule(t *testing.T) {
+
+	tests := []struct {
+		name  string
+		input string
+		edges int
+		path  []int // Critical path, also one instruction per round
+		width int   // Instructions of the first round
+	}{
+		// Every instruction touches a Stack of the previous one
+		{"sample", SAMPLE, 4, []int{0, 1, 2, 3}, 1},
+		// The first two moves touch disjoint Stacks and share a round
+		{"disjoint", "[A]     [B]    \n 1   2   3   4 \n\nmove 1 from 1 to 2\nmove 1 from 3 to 4\nmove 1 from 2 to 3\n", 2, []int{0, 2}, 2},
+	}
+	for _, tt := range tests {
+		engine, err := NewEngine(write(t, "input.txt", tt.input))
+		if err != nil {
+			t.Fatal(err)
+		}
+		sched := engine.Schedule(Strict)
+		if sched.stop != nil || len(sched.warnings) > 0 {
+			t.Fatalf("%s: unexpected errors %v %v", tt.name, sched.stop, sched.warnings)
+		}
+		if len(sched.rounds) != len(tt.path) {
+			t.Errorf("%s: %d rounds, want %d", tt.name, len(sched.rounds), len(tt.path))
+		}
+		if got := sched.CriticalPath(); !slices.Equal(got, tt.path) {
+			t.Errorf("%s: critical path %v, want %v", tt.name, got, tt.path)
+		}
+		if sched.edges != tt.edges {
+			t.Errorf("%s: %d dependencies, want %d", tt.name, sched.edges, tt.edges)
+		}
+		if width := len(sched.rounds[0]); width != tt.width {
+			t.Errorf("%s: %d instructions in the first round, want %d", tt.name, width, tt.width)
+		}
+	}
+}
+
+func benchmarkCargo(b *testing.B, capacity int) {
+
+	w := workload(BENCH_STACKS, BENCH_CRATES, BENCH_MOVES, 20, 1)
+	crane := NewCrateMover("CrateMover", capacity, 0, 1)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		cargo := linked(w)
+		b.StartTimer()
+		if err := cargo.Run(crane, Strict); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func benchmarkEngine(b *testing.B, capacity int) {
+
+	filename := saveTemp(b, workload(BENCH_STACKS, BENCH_CRATES, BENCH_MOVES, 20, 1))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		// Loading is timed too, as the Cargo parses every instruction while running
+		engine, err := NewEngine(filename)
+		if err != nil {
+			b.Fatal(err)
+		}
+		if err := engine.Run(capacity, Strict); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkCargo9000(b *testing.B)  { benchmarkCargo(b, 1) }
+func BenchmarkCargo9001(b *testing.B)  { benchmarkCargo(b, 0) }
+func BenchmarkEngine9000(b *testing.B) { benchmarkEngine(b, 1) }
+func BenchmarkEngine9001(b *testing.B) { benchmarkEngine(b, 0) }
@@ -5,12 +5,16 @@ package main
 
 import (
 	"bufio"
+	"bytes"
 	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"os"
 	"regexp"
-	"strconv"
+	"slices"
+	"strings"
+	"time"
 )
 
 // Crate class.
@@ -70,6 +74,27 @@ func (stack *Stack) Pop() (crate *Crate) {
 	return
 }
 
+// Pops the 'n' top Crates from the Stack, keeping their order (top last).
+func (stack *Stack) PopN(n int) (crates []*Crate) {
+
+	crates = make([]*Crate, n)
+	i := n - 1
+	for i >= 0 {
+		crates[i] = stack.Pop()
+		i -= 1
+	}
+	return
+}
+
+// Pushes Crates to the Stack in order, so the last one ends on top.
+func (stack *Stack) PushN(crates []*Crate) {
+
+	for _, crate := range crates {
+		stack.Push(crate)
+	}
+	return
+}
+
 // Prepends a Crate to the Stack (top-down approach)
 func (stack *Stack) Prepend(crate *Crate) {
 
@@ -80,17 +105,33 @@ func (stack *Stack) Prepend(crate *Crate) {
 		stack.bottom.prev = crate
 		stack.bottom = crate
 	}
+	stack.num += 1
 	return
 }
 
+// Line of the input file.
+type Line struct {
+	num  int // Line number, starting at 1
+	text string
+}
+
 // Cargo class.
 type Cargo struct {
 	num_stacks   int
 	num_crates   int
+	cost         int     // Cost of operating the crane
+	verbose      bool    // Prints the crates per stack after every step
+	warnings     []error // Instructions skipped in Lenient mode
 	stacks       []*Stack
-	instructions chan string
+	instructions chan Line
+	crane        Crane         // Crane stepping through the program
+	program      []Instruction // Loaded instructions
+	applied      []Applied     // Instructions applied so far
 }
 
+// Footer of the diagram, numbering the stacks.
+var footerRe = regexp.MustCompile(`^[0-9 ]+$`)
+
 // Cargo class constructor.
 func NewCargo(filename string) (cargo *Cargo) {
 
@@ -112,6 +153,14 @@ func NewCargo(filename string) (cargo *Cargo) {
 			if len(s) > 0 {
 				matched, _ := regexp.MatchString(`[A-Z]`, s)
 				if !matched {
+					// Reached the footer: it numbers every stack, even empty ones
+					if footerRe.MatchString(s) {
+						for num_stacks < len(strings.Fields(s)) {
+							fmt.Printf("Added a new stack %d\n", num_stacks)
+							stacks = append(stacks, NewStack())
+							num_stacks += 1
+						}
+					}
 					// Reached the instructions
 					break
 				}
@@ -135,8 +184,8 @@ func NewCargo(filename string) (cargo *Cargo) {
 					if r != ' ' {
 						stacks[i].Prepend(NewCrate(r))
 						fmt.Printf("Prepended crate %c to stack %d\n", r, i)
+						num_crates += 1
 					}
-					num_crates += 1
 					i += 1
 				}
 			}
@@ -146,25 +195,37 @@ func NewCargo(filename string) (cargo *Cargo) {
 	}
 	f.Close()
 
-	// Generate channel of string for the crane instructions
-	instructions := make(chan string, 1)
+	// Generate channel of lines for the crane instructions
+	instructions := make(chan Line, 1)
 	go func() {
 		f, err := os.Open(filename)
 		if err != nil {
 			defer close(instructions)
 		}
 
+		num := 0
+		diagram := true
 		r := bufio.NewReader(f)
 		for true {
 			s, err := r.ReadString('\n')
 			if !errors.Is(err, io.EOF) {
+				num += 1
 				s = s[:len(s)-1]
-				if len(s) > 0 && s[0] == 'm' {
-					// Add each instruction to the generator
-					instructions <- s
-				} else {
+				if len(s) == 0 {
 					continue
 				}
+				if diagram {
+					if matched, _ := regexp.MatchString(`[A-Z]`, s); matched {
+						continue
+					}
+					// Reached the footer and the instructions
+					diagram = false
+					if footerRe.MatchString(s) {
+						continue
+					}
+				}
+				// Add every other line to the generator, to be parsed by Run
+				instructions <- Line{num: num, text: s}
 			} else {
 				defer close(instructions)
 				break
@@ -182,74 +243,339 @@ func NewCargo(filename string) (cargo *Cargo) {
 	return
 }
 
+// Crates on top of each Stack.
+func (cargo *Cargo) Tops() string {
+
+	tops := make([]rune, 0, len(cargo.stacks))
+	for _, stack := range cargo.stacks {
+		if stack.top != nil {
+			tops = append(tops, stack.top.val)
+		}
+	}
+	return string(tops)
+}
+
 // Prints the Cargo object.
 func (cargo *Cargo) Print() {
 
-	fmt.Printf("Cargo has %d crates arranged in %d stacks > ", cargo.num_crates, cargo.num_stacks)
-	i := 0
-	for i < len(cargo.stacks) {
-		fmt.Printf("%c", cargo.stacks[i].top.val)
-		i += 1
+	fmt.Printf("Cargo has %d crates arranged in %d stacks > %s\n", cargo.num_crates, cargo.num_stacks, cargo.Tops())
+	return
+}
+
+// Validation mode of the instructions.
+type Mode int
+
+const (
+	Strict  Mode = iota // Stops at the first invalid instruction
+	Lenient             // Skips invalid instructions with a warning
+)
+
+// Number of Crates in each Stack.
+func (cargo *Cargo) Counts() (counts []int) {
+
+	counts = make([]int, len(cargo.stacks))
+	for i, stack := range cargo.stacks {
+		counts[i] = stack.num
 	}
-	fmt.Printf("\n")
 	return
 }
 
-// Moves the Crates from one Stack to another based on a set of instructions.
-func (cargo *Cargo) MoveCrates() {
-
-	for instruction := range cargo.instructions {
-		re := regexp.MustCompile(`[0-9]+`)
-		move := re.FindAllString(instruction, -1)
-		num, _ := strconv.Atoi(move[0]) // Number of crates to move
-		sc, _ := strconv.Atoi(move[1])
-		sc -= 1 // Source crate
-		tc, _ := strconv.Atoi(move[2])
-		tc -= 1 // Target crate
-		i := 0
-		for i < num {
-			cargo.stacks[tc].Push(cargo.stacks[sc].Pop())
-			fmt.Printf("Moving [%c] from %d to %d\n", cargo.stacks[tc].top.val, sc+1, tc+1)
-			i += 1
+// Checks that an Instruction can be executed on the Cargo.
+func (cargo *Cargo) Check(ins Instruction) error {
+
+	if ins.from < 0 || ins.from >= len(cargo.stacks) {
+		return fmt.Errorf("unknown source stack %d", ins.from+1)
+	}
+	if ins.to < 0 || ins.to >= len(cargo.stacks) {
+		return fmt.Errorf("unknown target stack %d", ins.to+1)
+	}
+	if ins.num <= 0 {
+		return fmt.Errorf("moving %d crates", ins.num)
+	}
+	if n := cargo.stacks[ins.from].num; n < ins.num {
+		return fmt.Errorf("stack %d underflows, it holds %d crates", ins.from+1, n)
+	}
+	return nil
+}
+
+// Error of an instruction on a line of the input, ending with a period.
+func lineError(num int, text any, err error) error {
+
+	return fmt.Errorf("Line %d: '%s': %s.", num, text, strings.TrimSuffix(err.Error(), "."))
+}
+
+// Executes the instructions through a Crane, adding up its operating cost.
+// Invalid instructions stop the execution in Strict mode, or are skipped with
+// a warning in Lenient mode.
+func (cargo *Cargo) Run(crane Crane, mode Mode) error {
+
+	for line := range cargo.instructions {
+		ins, err := ParseInstruction(line.text)
+		if err == nil {
+			err = cargo.Check(ins)
+		}
+		if err != nil {
+			err = lineError(line.num, line.text, err)
+			if mode == Strict {
+				// Drain the generator
+				for range cargo.instructions {
+				}
+				return err
+			}
+			fmt.Printf("Warning: %s Skipped.\n", err)
+			cargo.warnings = append(cargo.warnings, err)
+			continue
+		}
+		cargo.cost += crane.Move(cargo, ins)
+		if cargo.verbose {
+			fmt.Printf("Line %d: %s > crates per stack %v\n", line.num, ins, cargo.Counts())
 		}
 	}
-	return
+	fmt.Printf("%s operated at a total cost of %d\n", crane.Name(), cargo.cost)
+	return nil
+}
+
+// Moves the Crates from one Stack to another based on a set of instructions.
+func (cargo *Cargo) MoveCrates(mode Mode) error {
+
+	return cargo.Run(CrateMover9000(), mode)
 }
 
 // Moves groups of Crates from one Stack to another (CraneMover9001).
-func (cargo *Cargo) Move9001() {
-
-	for instruction := range cargo.instructions {
-		re := regexp.MustCompile(`[0-9]+`)
-		move := re.FindAllString(instruction, -1)
-		num, _ := strconv.Atoi(move[0]) // Number of crates to move
-		sc, _ := strconv.Atoi(move[1])
-		sc -= 1 // Source crate
-		tc, _ := strconv.Atoi(move[2])
-		tc -= 1 // Target crate
-		i := 0
-		var crates []*Crate
-		for i < num {
-			crates = append(crates, cargo.stacks[sc].Pop())
-			i += 1
+func (cargo *Cargo) Move9001(mode Mode) error {
+
+	return cargo.Run(CrateMover9001(), mode)
+}
+
+// Schedules the instructions into rounds of moves that can run at once.
+//
+// Usage: rounds [-strict]
+func rounds(filename string, args []string) {
+
+	cmd := flag.NewFlagSet("rounds", flag.ExitOnError)
+	strict := cmd.Bool("strict", false, "stop at the first invalid instruction instead of skipping it")
+	cmd.Parse(args)
+
+	engine, err := NewEngine(filename)
+	if err != nil {
+		panic(err)
+	}
+	engine.Schedule(modeOf(*strict)).Print(engine, 10)
+	return
+}
+
+// Compares the sequential and the parallel runs of the Engine.
+//
+// Usage: parallel [-workers N] [-strict]
+func parallel(filename string, args []string) {
+
+	cmd := flag.NewFlagSet("parallel", flag.ExitOnError)
+	workers := cmd.Int("workers", 4, "crane workers of the parallel run")
+	strict := cmd.Bool("strict", false, "stop at the first invalid instruction instead of skipping it")
+	cmd.Parse(args)
+
+	mode := modeOf(*strict)
+	engine, err := NewEngine(filename)
+	if err != nil {
+		panic(err)
+	}
+	for _, c := range []int{1, 0} {
+		start := time.Now()
+		err := engine.Run(c, mode)
+		sequential := time.Since(start)
+		stacks, cost := engine.Snapshot(), engine.cost
+		start = time.Now()
+		if perr := engine.RunParallel(c, *workers, mode); (perr == nil) != (err == nil) {
+			fmt.Printf("Parallel run stopped at %v, the sequential one at %v\n", perr, err)
 		}
-		i = num - 1
-		for i >= 0 {
-			cargo.stacks[tc].Push(crates[i])
-			i -= 1
+		parallel := time.Since(start)
+		fmt.Printf("Capacity %d: sequential %v, %d workers %v\n", c, sequential, *workers, parallel)
+		switch {
+		case !slices.EqualFunc(stacks, engine.stacks, bytes.Equal):
+			for i := range stacks {
+				if !bytes.Equal(stacks[i], engine.stacks[i]) {
+					fmt.Printf("Parallel run differs: stack %d holds '%s', the sequential one '%s'\n", i+1, engine.stacks[i], stacks[i])
+					break
+				}
+			}
+		case cost != engine.cost:
+			fmt.Printf("Parallel run differs: cost %d, the sequential one %d\n", engine.cost, cost)
+		default:
+			fmt.Printf("Both runs end with the same Cargo > %s\n", engine.Tops())
 		}
 	}
 	return
 }
 
+// Plans the moves of a crane rearranging the Cargo into a target diagram.
+//
+// Usage: plan [-crane MODEL] [-target FILE] [-method NAME]
+func plan(filename string, args []string) {
+
+	cmd := flag.NewFlagSet("plan", flag.ExitOnError)
+	model := cmd.String("crane", "9001", "crane: 9000, 9001 or a lift capacity")
+	target := cmd.String("target", "target.txt", "diagram of the arrangement to plan for")
+	method := cmd.String("method", "auto", "planner: exact, greedy or auto")
+	cmd.Parse(args)
+
+	crane, err := NewCrane(*model)
+	if err != nil {
+		panic(err)
+	}
+	cargo := NewCargo(filename)
+	goal := NewCargo(*target)
+	plan, err := cargo.PlanTo(goal, crane, *method)
+	if err == nil {
+		plan.Print()
+		err = plan.Verify(cargo, goal)
+	}
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("Verified: replaying the %d moves reaches the target.\n", len(plan.moves))
+	return
+}
+
+// Loads the Cargo for a crane, exiting on an invalid crane.
+func load(filename string, model string) (cargo *Cargo) {
+
+	crane, err := NewCrane(model)
+	if err != nil {
+		panic(err)
+	}
+	cargo = NewCargo(filename)
+	cargo.Load(crane)
+	return
+}
+
+// Steps through the moves of a crane interactively.
+//
+// Usage: repl [-crane MODEL]
+func repl(filename string, args []string) {
+
+	cmd := flag.NewFlagSet("repl", flag.ExitOnError)
+	model := cmd.String("crane", "9001", "crane: 9000, 9001 or a lift capacity")
+	cmd.Parse(args)
+
+	load(filename, *model).REPL(os.Stdin, os.Stdout)
+	return
+}
+
+// Animates the moves of a crane.
+//
+// Usage: animate [-crane MODEL] [-delay D]
+func animate(filename string, args []string) {
+
+	cmd := flag.NewFlagSet("animate", flag.ExitOnError)
+	model := cmd.String("crane", "9001", "crane: 9000, 9001 or a lift capacity")
+	delay := cmd.Duration("delay", 500*time.Millisecond, "delay between the moves of the animation")
+	cmd.Parse(args)
+
+	if err := load(filename, *model).Animate(os.Stdout, *delay); err != nil {
+		fmt.Println(err)
+	}
+	return
+}
+
+// Saves the Cargo after some moves of a crane.
+//
+// Usage: save [-crane MODEL] [-seek N] [-o FILE]
+func save(filename string, args []string) {
+
+	cmd := flag.NewFlagSet("save", flag.ExitOnError)
+	model := cmd.String("crane", "9001", "crane: 9000, 9001 or a lift capacity")
+	seek := cmd.Int("seek", 0, "moves to apply before saving")
+	output := cmd.String("o", "cargo.txt", "file to save the Cargo to")
+	cmd.Parse(args)
+
+	cargo := load(filename, *model)
+	err := cargo.Seek(*seek)
+	if err == nil {
+		err = cargo.SaveFile(*output)
+	}
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Printf("Saved the Cargo after move %d to %s\n", cargo.Position(), *output)
+	return
+}
+
+// Mode of the -strict flag.
+func modeOf(strict bool) (mode Mode) {
+
+	mode = Lenient
+	if strict {
+		mode = Strict
+	}
+	return
+}
+
 func main() {
 
+	capacity := flag.Int("capacity", 0, "run a custom crane lifting up to this many crates at once")
+	perCrate := flag.Int("crate-cost", 0, "cost of moving a crate with the custom crane")
+	perLift := flag.Int("lift-cost", 1, "cost of a lift of the custom crane")
+	strict := flag.Bool("strict", false, "stop at the first invalid instruction instead of skipping it")
+	verbose := flag.Bool("v", false, "print the crates per stack after every instruction")
+	fast := flag.Bool("fast", false, "run the cranes on the slice-backed Engine")
+	flag.Parse()
+
 	const filename string = "input.txt"
+	switch flag.Arg(0) {
+	case "rounds":
+		rounds(filename, flag.Args()[1:])
+		return
+	case "parallel":
+		parallel(filename, flag.Args()[1:])
+		return
+	case "plan":
+		plan(filename, flag.Args()[1:])
+		return
+	case "repl":
+		repl(filename, flag.Args()[1:])
+		return
+	case "animate":
+		animate(filename, flag.Args()[1:])
+		return
+	case "save":
+		save(filename, flag.Args()[1:])
+		return
+	}
+	mode := modeOf(*strict)
+	if *fast {
+		engine, err := NewEngine(filename)
+		if err != nil {
+			panic(err)
+		}
+		for _, c := range []int{1, 0} {
+			if err := engine.Run(c, mode); err != nil {
+				fmt.Println(err)
+			}
+			fmt.Printf("Engine with capacity %d operated at a total cost of %d > %s\n", c, engine.cost, engine.Tops())
+		}
+		return
+	}
 	cargo := NewCargo(filename)
+	cargo.verbose = *verbose
 	cargo.Print()
-	cargo.MoveCrates()
+	if err := cargo.MoveCrates(mode); err != nil {
+		fmt.Println(err)
+	}
 	cargo.Print()
 	cargo9k1 := NewCargo(filename)
-	cargo9k1.Move9001()
+	cargo9k1.verbose = *verbose
+	if err := cargo9k1.Move9001(mode); err != nil {
+		fmt.Println(err)
+	}
 	cargo9k1.Print()
+	if *capacity > 0 {
+		custom := NewCargo(filename)
+		custom.verbose = *verbose
+		if err := custom.Run(NewCrateMover(fmt.Sprintf("CrateMover (capacity %d)", *capacity), *capacity, *perCrate, *perLift), mode); err != nil {
+			fmt.Println(err)
+		}
+		custom.Print()
+	}
 }
@@ -0,0 +1,171 @@
+// Miguel Nobre Castro
+// https://adventofcode.com/2022/day/5
+
+package main
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Schedule of the instructions of an Engine in rounds: an instruction depends
+// on the last earlier ones moving crates from or to any of its Stacks, and
+// all the instructions of a round touch disjoint Stacks.
+type Schedule struct {
+	rounds   [][]int // Instructions (indices in the program) of each round
+	edges    int     // Dependencies between instructions
+	pred     []int   // Dependency of each instruction in the latest round, or -1
+	level    []int   // Round of each instruction, starting at 1, or 0 if skipped
+	warnings []error // Instructions skipped, in order
+	stop     error   // First invalid instruction in Strict mode
+}
+
+// Builds the dependency graph of the instructions and schedules each one in
+// the round after its latest dependency, so there are as many rounds as
+// instructions in the longest path of the graph. Invalid instructions are
+// found replaying only the number of crates per Stack, and are skipped or
+// stop the Schedule as in Engine.Run.
+func (engine *Engine) Schedule(mode Mode) (sched *Schedule) {
+
+	sched = &Schedule{
+		pred:  make([]int, len(engine.program)),
+		level: make([]int, len(engine.program)),
+	}
+	heights := make([]int, len(engine.initial))
+	last := make([]int, len(engine.initial)) // Last instruction touching each Stack
+	for i, crates := range engine.initial {
+		heights[i] = len(crates)
+		last[i] = -1
+	}
+
+	for k, p := range engine.program {
+		sched.pred[k] = -1
+		err := p.err
+		if err == nil {
+			err = validate(p.ins, len(heights), func(i int) int { return heights[i] })
+			if err != nil {
+				err = lineError(p.ins.line, p.ins, err)
+			}
+		}
+		if err != nil {
+			if mode == Strict {
+				sched.stop = err
+				break
+			}
+			sched.warnings = append(sched.warnings, err)
+			continue
+		}
+		heights[p.ins.from] -= p.ins.num
+		heights[p.ins.to] += p.ins.num
+
+		from, to := last[p.ins.from], last[p.ins.to]
+		if from >= 0 {
+			sched.edges += 1
+		}
+		if to >= 0 && to != from {
+			sched.edges += 1
+		}
+		for _, j := range []int{from, to} {
+			if j >= 0 && (sched.pred[k] < 0 || sched.level[j] > sched.level[sched.pred[k]]) {
+				sched.pred[k] = j
+			}
+		}
+		level := 1
+		if sched.pred[k] >= 0 {
+			level = sched.level[sched.pred[k]] + 1
+		}
+		sched.level[k] = level
+		last[p.ins.from], last[p.ins.to] = k, k
+		if level > len(sched.rounds) {
+			sched.rounds = append(sched.rounds, nil)
+		}
+		sched.rounds[level-1] = append(sched.rounds[level-1], k)
+	}
+	return
+}
+
+// Instructions (indices in the program) of the longest path of dependencies.
+func (sched *Schedule) CriticalPath() (path []int) {
+
+	if len(sched.rounds) == 0 {
+		return
+	}
+	for k := sched.rounds[len(sched.rounds)-1][0]; k >= 0; k = sched.pred[k] {
+		path = append(path, k)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	return
+}
+
+// Prints the analysis of the Schedule, listing up to 'limit' instructions of
+// the critical path.
+func (sched *Schedule) Print(engine *Engine, limit int) {
+
+	valid, widest := 0, 0
+	for _, round := range sched.rounds {
+		valid += len(round)
+		widest = max(widest, len(round))
+	}
+	fmt.Printf("%d instructions, %d skipped, with %d dependencies between them\n", valid, len(sched.warnings), sched.edges)
+	if sched.stop != nil {
+		fmt.Printf("Stopped at %s\n", sched.stop)
+	}
+	if len(sched.rounds) == 0 {
+		return
+	}
+	fmt.Printf("Critical path of %d instructions: cranes need %d rounds, up to %d moves per round (%.1f on average)\n",
+		len(sched.rounds), len(sched.rounds), widest, float64(valid)/float64(len(sched.rounds)))
+	for n, k := range sched.CriticalPath() {
+		if n == limit {
+			fmt.Printf("...\n")
+			break
+		}
+		fmt.Printf("  Round %d: line %d: %s\n", n+1, engine.program[k].ins.line, engine.program[k].ins)
+	}
+	return
+}
+
+// Executes the instructions round by round with several crane workers
+// lifting up to 'capacity' crates at once (0 for no limit). The moves of a
+// round touch disjoint Stacks, so they run concurrently and the Stacks end as
+// in Engine.Run.
+func (engine *Engine) RunParallel(capacity int, workers int, mode Mode) error {
+
+	sched := engine.Schedule(mode)
+	for _, err := range sched.warnings {
+		fmt.Printf("Warning: %s Skipped.\n", err)
+	}
+	engine.reset()
+	workers = max(workers, 1)
+
+	moves := make(chan Instruction, workers)
+	lifts := make([]int, workers) // Cost of each worker
+	var round sync.WaitGroup
+	var done sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		done.Add(1)
+		go func(w int) {
+			defer done.Done()
+			for ins := range moves {
+				lifts[w] += engine.move(ins, capacity)
+				round.Done()
+			}
+		}(w)
+	}
+	for _, r := range sched.rounds {
+		round.Add(len(r))
+		for _, k := range r {
+			moves <- engine.program[k].ins
+		}
+		round.Wait()
+	}
+	close(moves)
+	done.Wait()
+
+	for _, n := range lifts {
+		engine.cost += n
+	}
+	return sched.stop
+}
@@ -0,0 +1,314 @@
+// Miguel Nobre Castro
+// https://adventofcode.com/2022/day/5
+
+package main
+
+import (
+	"container/heap"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// Maximum number of crates planned exactly by the "auto" method.
+const EXACT_MAX_CRATES int = 10
+
+// Maximum number of arrangements explored by the exact planner.
+const EXACT_MAX_STATES int = 200000
+
+// Plan of the instructions taking a Cargo to a target arrangement.
+type Plan struct {
+	crane  Crane
+	method string // Planner used ("exact" or "greedy")
+	moves  []Instruction
+}
+
+// Crates of every Stack, from the bottom to the top.
+func (cargo *Cargo) Arrangement() (stacks [][]rune) {
+
+	stacks = make([][]rune, len(cargo.stacks))
+	for i, stack := range cargo.stacks {
+		stacks[i] = stack.Crates()
+	}
+	return
+}
+
+// Cargo holding an arrangement of crates, without instructions.
+func arranged(stacks [][]rune) (cargo *Cargo) {
+
+	cargo = &Cargo{num_stacks: len(stacks)}
+	for _, crates := range stacks {
+		stack := NewStack()
+		for _, r := range crates {
+			stack.Push(NewCrate(r))
+		}
+		cargo.stacks = append(cargo.stacks, stack)
+		cargo.num_crates += len(crates)
+	}
+	return
+}
+
+// Arrangement after the crane executes an Instruction.
+func apply(stacks [][]rune, crane Crane, ins Instruction) [][]rune {
+
+	cargo := arranged(stacks)
+	crane.Move(cargo, ins)
+	return cargo.Arrangement()
+}
+
+// Number of bottom crates of each Stack already in their target place.
+func settled(stacks [][]rune, target [][]rune) (fixed []int) {
+
+	fixed = make([]int, len(stacks))
+	for i := range stacks {
+		for fixed[i] < len(stacks[i]) && fixed[i] < len(target[i]) && stacks[i][fixed[i]] == target[i][fixed[i]] {
+			fixed[i] += 1
+		}
+	}
+	return
+}
+
+// Lower bound of the moves left: every Stack with misplaced crates needs a
+// move from it, and every Stack missing crates needs a move to it.
+func remaining(stacks [][]rune, target [][]rune) int {
+
+	out, in := 0, 0
+	for i, f := range settled(stacks, target) {
+		if len(stacks[i]) > f {
+			out += 1
+		}
+		if len(target[i]) > f {
+			in += 1
+		}
+	}
+	return max(out, in)
+}
+
+func key(stacks [][]rune) string {
+
+	s := make([]string, len(stacks))
+	for i, crates := range stacks {
+		s[i] = string(crates)
+	}
+	return strings.Join(s, "|")
+}
+
+// Plans the instructions for a crane taking the Cargo to the arrangement of
+// 'target', with the fewest moves. The 'method' is one of "exact" (A* search),
+// "greedy" or "auto", which solves exactly up to EXACT_MAX_CRATES crates and
+// falls back to "greedy" otherwise.
+func (cargo *Cargo) PlanTo(target *Cargo, crane Crane, method string) (plan *Plan, err error) {
+
+	start, goal := cargo.Arrangement(), target.Arrangement()
+	if len(start) != len(goal) {
+		return nil, fmt.Errorf("Planner: %d stacks can't be arranged into %d.", len(start), len(goal))
+	}
+	counts := make(map[rune]int)
+	for i := range start {
+		for _, r := range start[i] {
+			counts[r] += 1
+		}
+		for _, r := range goal[i] {
+			counts[r] -= 1
+		}
+	}
+	for r, n := range counts {
+		if n != 0 {
+			return nil, fmt.Errorf("Planner: the target has %d crates %c too many.", -n, r)
+		}
+	}
+
+	plan = &Plan{crane: crane, method: method}
+	switch method {
+	case "auto":
+		if cargo.num_crates <= EXACT_MAX_CRATES {
+			plan.method = "exact"
+			plan.moves, err = planExact(start, goal, crane)
+		}
+		if cargo.num_crates > EXACT_MAX_CRATES || err != nil {
+			plan.method = "greedy"
+			plan.moves, err = planGreedy(start, goal, crane)
+		}
+	case "exact":
+		plan.moves, err = planExact(start, goal, crane)
+	case "greedy":
+		plan.moves, err = planGreedy(start, goal, crane)
+	default:
+		return nil, fmt.Errorf("Planner: unknown method '%s'.", method)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return
+}
+
+// Node of the A* search.
+type node struct {
+	stacks [][]rune
+	moves  []Instruction
+	f      int // Moves so far plus the lower bound of the moves left
+}
+
+// Min-heap of nodes ordered by 'f', then by the most moves so far.
+type nodeHeap []*node
+
+func (h nodeHeap) Len() int { return len(h) }
+func (h nodeHeap) Less(i, j int) bool {
+	if h[i].f != h[j].f {
+		return h[i].f < h[j].f
+	}
+	return len(h[i].moves) > len(h[j].moves)
+}
+func (h nodeHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+
+func (h *nodeHeap) Push(x any) {
+
+	*h = append(*h, x.(*node))
+}
+
+func (h *nodeHeap) Pop() any {
+
+	old := *h
+	n := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return n
+}
+
+// A* search over the arrangements, trying every move of every number of
+// crates between every pair of Stacks.
+func planExact(start [][]rune, goal [][]rune, crane Crane) (moves []Instruction, err error) {
+
+	best := map[string]int{key(start): 0}
+	h := &nodeHeap{{stacks: start, f: remaining(start, goal)}}
+	explored := 0
+	for h.Len() > 0 {
+		n := heap.Pop(h).(*node)
+		if remaining(n.stacks, goal) == 0 {
+			return n.moves, nil
+		}
+		if best[key(n.stacks)] < len(n.moves) {
+			continue
+		}
+		explored += 1
+		if explored > EXACT_MAX_STATES {
+			return nil, fmt.Errorf("Planner: explored more than %d arrangements.", EXACT_MAX_STATES)
+		}
+		for from := range n.stacks {
+			for to := range n.stacks {
+				if from == to {
+					continue
+				}
+				for num := 1; num <= len(n.stacks[from]); num++ {
+					ins := Instruction{num: num, from: from, to: to}
+					next := apply(n.stacks, crane, ins)
+					k := key(next)
+					if g, ok := best[k]; ok && g <= len(n.moves)+1 {
+						continue
+					}
+					best[k] = len(n.moves) + 1
+					heap.Push(h, &node{
+						stacks: next,
+						moves:  append(slices.Clip(n.moves), ins),
+						f:      len(n.moves) + 1 + remaining(next, goal),
+					})
+				}
+			}
+		}
+	}
+	return nil, errors.New("Planner: the target can't be reached.")
+}
+
+// Builds the target Stacks from the bottom up, one crate at a time: clears
+// the misplaced crates of a Stack, digs up its next crate and moves it in.
+// Cleared and dug crates go in a single move to a third Stack, so it needs at
+// least three Stacks and takes up to three moves per crate.
+func planGreedy(start [][]rune, goal [][]rune, crane Crane) (moves []Instruction, err error) {
+
+	stacks := start
+	move := func(num int, from int, to int) {
+		if num > 0 {
+			ins := Instruction{num: num, from: from, to: to}
+			stacks = apply(stacks, crane, ins)
+			moves = append(moves, ins)
+		}
+	}
+	// Lowest Stack other than 'i' and 'j'
+	spare := func(i int, j int) (k int) {
+		k = -1
+		for s := range stacks {
+			if s != i && s != j && (k < 0 || len(stacks[s]) < len(stacks[k])) {
+				k = s
+			}
+		}
+		return
+	}
+	// Highest copy of the crate 'r' out of place, but not in Stack 'i'
+	find := func(r rune, i int, fixed []int) (j int, depth int) {
+		j, depth = -1, 0
+		for s := range stacks {
+			for p := len(stacks[s]) - 1; p >= fixed[s] && s != i; p-- {
+				if stacks[s][p] == r && (j < 0 || len(stacks[s])-1-p < depth) {
+					j, depth = s, len(stacks[s])-1-p
+				}
+			}
+		}
+		return
+	}
+
+	for remaining(stacks, goal) > 0 {
+		if len(stacks) < 3 {
+			return nil, errors.New("Planner: the greedy planner needs at least 3 stacks.")
+		}
+		// Cheapest Stack to extend: fewest crates to clear and dig
+		fixed := settled(stacks, goal)
+		i, cost := -1, 0
+		for s := range stacks {
+			if fixed[s] == len(goal[s]) {
+				continue
+			}
+			c := len(stacks[s]) - fixed[s]
+			if j, depth := find(goal[s][fixed[s]], s, fixed); j >= 0 {
+				c += depth
+			}
+			if i < 0 || c < cost {
+				i, cost = s, c
+			}
+		}
+		r := goal[i][fixed[i]]
+		j, _ := find(r, i, fixed)
+		move(len(stacks[i])-fixed[i], i, spare(i, j))
+		fixed = settled(stacks, goal)
+		j, depth := find(r, i, fixed)
+		move(depth, j, spare(i, j))
+		move(1, j, i)
+	}
+	return
+}
+
+// Replays the Plan through the crane on a copy of the Cargo, checking every
+// instruction, and checks that it reaches the arrangement of 'target'.
+func (plan *Plan) Verify(cargo *Cargo, target *Cargo) error {
+
+	replay := arranged(cargo.Arrangement())
+	for k, ins := range plan.moves {
+		if err := replay.Check(ins); err != nil {
+			return fmt.Errorf("Planner: move %d '%s': %s.", k+1, ins, err)
+		}
+		plan.crane.Move(replay, ins)
+	}
+	if key(replay.Arrangement()) != key(target.Arrangement()) {
+		return errors.New("Planner: the plan doesn't reach the target.")
+	}
+	return nil
+}
+
+// Prints the Plan as instructions.
+func (plan *Plan) Print() {
+
+	fmt.Printf("%s reaches the target in %d moves (%s planner):\n", plan.crane.Name(), len(plan.moves), plan.method)
+	for _, ins := range plan.moves {
+		fmt.Printf("%s\n", ins)
+	}
+	return
+}
@@ -0,0 +1,183 @@
+// Miguel Nobre Castro
+// https://adventofcode.com/2022/day/5
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// Instruction applied to the Cargo, recorded to be undone.
+type Applied struct {
+	ins    Instruction
+	crates []*Crate // Moved Crates as they were on the source Stack (top last)
+	cost   int
+}
+
+// Crates of the Stack, from the bottom to the top.
+func (stack *Stack) Crates() (crates []rune) {
+
+	crates = make([]rune, stack.num)
+	i := stack.num - 1
+	ptr := stack.top
+	for ptr != nil && i >= 0 {
+		crates[i] = ptr.val
+		ptr = ptr.prev
+		i -= 1
+	}
+	return
+}
+
+// Loads all the instructions of the Cargo to step through them with a Crane.
+// Instructions that can't be parsed are skipped with a warning.
+func (cargo *Cargo) Load(crane Crane) {
+
+	cargo.crane = crane
+	for line := range cargo.instructions {
+		ins, err := ParseInstruction(line.text)
+		if err != nil {
+			err = lineError(line.num, line.text, err)
+			fmt.Printf("Warning: %s Skipped.\n", err)
+			cargo.warnings = append(cargo.warnings, err)
+			continue
+		}
+		ins.line = line.num
+		cargo.program = append(cargo.program, ins)
+	}
+	return
+}
+
+// Number of loaded instructions applied so far.
+func (cargo *Cargo) Position() int {
+
+	return len(cargo.applied)
+}
+
+// Applies the next loaded instruction, or redoes the last undone one.
+func (cargo *Cargo) Step() error {
+
+	if len(cargo.applied) == len(cargo.program) {
+		return errors.New("Cargo: no instructions left.")
+	}
+	ins := cargo.program[len(cargo.applied)]
+	if err := cargo.Check(ins); err != nil {
+		return lineError(ins.line, ins, err)
+	}
+	// The moved crates are the top ones of the source Stack
+	crates := make([]*Crate, 0, ins.num)
+	ptr := cargo.stacks[ins.from].top
+	for len(crates) < ins.num {
+		crates = append(crates, ptr)
+		ptr = ptr.prev
+	}
+	for i, j := 0, len(crates)-1; i < j; i, j = i+1, j-1 {
+		crates[i], crates[j] = crates[j], crates[i]
+	}
+	cost := cargo.crane.Move(cargo, ins)
+	cargo.cost += cost
+	cargo.applied = append(cargo.applied, Applied{ins: ins, crates: crates, cost: cost})
+	return nil
+}
+
+// Undoes the last applied instruction.
+func (cargo *Cargo) Undo() error {
+
+	if len(cargo.applied) == 0 {
+		return errors.New("Cargo: no instructions to undo.")
+	}
+	last := cargo.applied[len(cargo.applied)-1]
+	cargo.stacks[last.ins.to].PopN(last.ins.num)
+	cargo.stacks[last.ins.from].PushN(last.crates)
+	cargo.cost -= last.cost
+	cargo.applied = cargo.applied[:len(cargo.applied)-1]
+	return nil
+}
+
+// Steps forward or backward until 'n' instructions are applied.
+func (cargo *Cargo) Seek(n int) error {
+
+	if n < 0 || n > len(cargo.program) {
+		return fmt.Errorf("Cargo: move %d is out of 0..%d.", n, len(cargo.program))
+	}
+	for len(cargo.applied) > n {
+		if err := cargo.Undo(); err != nil {
+			return err
+		}
+	}
+	for len(cargo.applied) < n {
+		if err := cargo.Step(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Prints the Crates of every Stack, from the bottom to the top.
+func (cargo *Cargo) Inspect(out io.Writer) {
+
+	fmt.Fprintf(out, "After move %d of %d (cost %d):\n", len(cargo.applied), len(cargo.program), cargo.cost)
+	for i, stack := range cargo.stacks {
+		fmt.Fprintf(out, "%d: %s\n", i+1, string(stack.Crates()))
+	}
+	return
+}
+
+// Interactive REPL to travel through the moves of the Cargo.
+func (cargo *Cargo) REPL(in io.Reader, out io.Writer) {
+
+	help := "Commands: step [n], undo [n], seek N, show, tops, help, quit"
+	fmt.Fprintf(out, "%s with %d moves. %s\n", cargo.crane.Name(), len(cargo.program), help)
+	r := bufio.NewScanner(in)
+	fmt.Fprintf(out, "> ")
+	for r.Scan() {
+		fields := strings.Fields(r.Text())
+		count := 1
+		var err error
+		if len(fields) > 1 {
+			count, err = strconv.Atoi(fields[1])
+		}
+		if len(fields) == 0 || err != nil {
+			fmt.Fprintln(out, help)
+			fmt.Fprintf(out, "> ")
+			continue
+		}
+		switch fields[0] {
+		case "step", "s":
+			for i := 0; i < count && err == nil; i++ {
+				err = cargo.Step()
+			}
+		case "undo", "u":
+			for i := 0; i < count && err == nil; i++ {
+				err = cargo.Undo()
+			}
+		case "seek":
+			if len(fields) != 2 {
+				err = errors.New("Usage: seek N")
+			} else {
+				err = cargo.Seek(count)
+			}
+		case "show":
+			cargo.Inspect(out)
+		case "tops":
+			fmt.Fprintf(out, "%s\n", cargo.Tops())
+		case "quit", "q":
+			return
+		default:
+			fmt.Fprintln(out, help)
+		}
+		if err != nil {
+			fmt.Fprintln(out, err)
+		}
+		if fields[0] != "show" && fields[0] != "tops" && fields[0] != "help" {
+			fmt.Fprintf(out, "At move %d of %d, tops %s\n", cargo.Position(), len(cargo.program), cargo.Tops())
+		}
+		fmt.Fprintf(out, "> ")
+	}
+	fmt.Fprintln(out)
+	return
+}
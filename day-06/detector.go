@@ -0,0 +1,88 @@
+// Miguel Nobre Castro
+// https://adventofcode.com/2022/day/6
+
+package main
+
+// Count of a rune in the window and the last position it was read at.
+type entry struct {
+	count int
+	last  int
+}
+
+// Detector of markers: windows of 'size' characters that are all different.
+// It keeps the last characters in a ring and their counts in a table, so each
+// character is checked in O(1) whatever the window size.
+type Detector struct {
+	size  int
+	num   int             // Characters read so far
+	ring  []rune          // Last 'size' characters
+	ascii [128]entry      // Table of the ASCII runes
+	wide  map[rune]*entry // Table of the other runes
+	dups  int             // Runes read more than once in the window
+	ready int             // First position a marker can end at
+}
+
+// Detector constructor for windows of 'size' characters (at least 1).
+func NewDetector(size int) (det *Detector) {
+
+	size = max(size, 1)
+	det = &Detector{
+		size:  size,
+		ring:  make([]rune, size),
+		wide:  make(map[rune]*entry),
+		ready: size,
+	}
+	return
+}
+
+// Table entry of a rune.
+func (det *Detector) entry(r rune) *entry {
+
+	if r >= 0 && r < 128 {
+		return &det.ascii[r]
+	}
+	e, ok := det.wide[r]
+	if !ok {
+		e = &entry{}
+		det.wide[r] = e
+	}
+	return e
+}
+
+// Reads a character and reports whether the window ending on it is a marker.
+func (det *Detector) Push(r rune) bool {
+
+	slot := det.num % det.size
+	if det.num >= det.size {
+		// The oldest character leaves the window
+		out := det.entry(det.ring[slot])
+		out.count -= 1
+		if out.count == 1 {
+			det.dups -= 1
+		}
+	}
+	det.ring[slot] = r
+	det.num += 1
+
+	in := det.entry(r)
+	in.count += 1
+	if in.count == 2 {
+		det.dups += 1
+	}
+	// No window holding both copies of 'r' can be a marker
+	if in.last > 0 && in.last+det.size > det.ready {
+		det.ready = in.last + det.size
+	}
+	in.last = det.num
+	return det.Marker()
+}
+
+// Whether the window ending on the last character read is a marker.
+func (det *Detector) Marker() bool {
+
+	if det.num < det.ready {
+		// Skip checking the counts until the last repeated rune leaves
+		return false
+	}
+	return det.dups == 0
+}
@@ -0,0 +1,89 @@
+// Miguel Nobre Castro
+// https://adventofcode.com/2022/day/6
+
+package main
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// Position of the first marker of 'size' characters, or 0.
+func first(signal string, size int) int {
+
+	det := NewDetector(size)
+	for i, r := range []rune(signal) {
+		if det.Push(r) {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// Whether the last 'size' runes ending at position 'num' are all different.
+func distinct(runes []rune, num int, size int) bool {
+
+	if num < size {
+		return false
+	}
+	seen := make(map[rune]bool, size)
+	for _, r := range runes[num-size : num] {
+		if seen[r] {
+			return false
+		}
+		seen[r] = true
+	}
+	return true
+}
+
+func TestSamples(t *testing.T) {
+
+	tests := []struct {
+		signal  string
+		packet  int // Marker of 4 characters
+		message int // Marker of 14 characters
+	}{
+		{"mjqjpqmgbljsphdztnvjfqwrcgsmlb", 7, 19},
+		{"bvwbjplbgvbhsrlpgdmjqwftvncz", 5, 23},
+		{"nppdvjthqldpwncqszvftbrmjlhg", 6, 23},
+		{"nznrnfrfntjfmvfwmzdfjlvtqnbhcprsg", 10, 29},
+		{"zcfzfwzzqfrljwzlrfnpqdbhtmscgvjw", 11, 26},
+		{"aaaa", 0, 0},
+	}
+	for _, tt := range tests {
+		if got := first(tt.signal, 4); got != tt.packet {
+			t.Errorf("%s: packet marker after %d, want %d", tt.signal, got, tt.packet)
+		}
+		if got := first(tt.signal, 14); got != tt.message {
+			t.Errorf("%s: message marker after %d, want %d", tt.signal, got, tt.message)
+		}
+	}
+}
+
+func TestDetectorMatchesBruteForce(t *testing.T) {
+
+	alphabets := [][]rune{
+		[]rune("ab"),
+		[]rune("abcdefgh"),
+		[]rune("aé€😀"),
+		[]rune("xyzçñßπλ日本語😀🎄"),
+	}
+	rng := rand.New(rand.NewSource(1))
+	for n := 0; n < 20000; n++ {
+		alphabet := alphabets[n%len(alphabets)]
+		size := 1 + rng.Intn(8)
+		runes := make([]rune, rng.Intn(60))
+		for i := range runes {
+			runes[i] = alphabet[rng.Intn(len(alphabet))]
+		}
+		det := NewDetector(size)
+		for i, r := range runes {
+			if got, want := det.Push(r), distinct(runes, i+1, size); got != want {
+				t.Fatalf("%q, window %d: marker %v after character %d, want %v", string(runes), size, got, i+1, want)
+			}
+			if det.Marker() != distinct(runes, i+1, size) {
+				t.Fatalf("%q, window %d: Marker() disagrees with Push after character %d", string(runes), size, i+1)
+			}
+		}
+	}
+}
@@ -6,6 +6,7 @@ package main
 import (
 	"bufio"
 	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"os"
@@ -35,6 +36,7 @@ type Buffer struct {
 	head   *Data
 	tail   *Data
 	signal chan rune
+	marker *Detector // Markers of MAXLEN characters
 }
 
 // Buffer struct constructor.
@@ -66,6 +68,7 @@ func NewBuffer(MAXLEN int, filename string) (buff *Buffer) {
 		head:   nil,
 		tail:   nil,
 		signal: signal,
+		marker: NewDetector(MAXLEN),
 	}
 	return
 }
@@ -85,6 +88,7 @@ func (buff *Buffer) Enqueue(data *Data) {
 	buff.tail = data
 	buff.length += 1
 	buff.num += 1
+	buff.marker.Push(data.val)
 	return
 }
 
@@ -101,13 +105,14 @@ func (buff *Buffer) Dequeue() error {
 	return nil
 }
 
-// Reads the signal to detect a start-of-packet marker.
+// Reads the signal to detect a start-of-packet marker. The characters go
+// straight to the Detector, without queueing them in the Buffer.
 func (buff *Buffer) Read() (num int) {
 
 	num = 0
 	for char := range buff.signal {
-		buff.Enqueue(NewData(char))
-		if buff.IsMarker() {
+		buff.num += 1
+		if buff.marker.Push(char) {
 			num = buff.num
 			break
 		}
@@ -116,29 +121,16 @@ func (buff *Buffer) Read() (num int) {
 }
 
 // Detects a start-of-packet marker.
-func (buff *Buffer) IsMarker() (b bool) {
+func (buff *Buffer) IsMarker() bool {
 
-	b = true
-	if buff.length < buff.MAXLEN {
-		b = false
-	} else {
-		ptr1 := buff.head
-		for ptr1 != buff.tail && b {
-			ptr2 := ptr1.next
-			for ptr2 != nil && b {
-				if ptr1.val == ptr2.val {
-					b = false
-				}
-				ptr2 = ptr2.next
-			}
-			ptr1 = ptr1.next
-		}
-	}
-	return
+	return buff.marker.Marker()
 }
 
 func main() {
 
+	window := flag.Int("window", 0, "also detect markers of this many characters")
+	flag.Parse()
+
 	const filename string = "input.txt"
 	signal := NewBuffer(4, filename)
 	val := signal.Read()
@@ -154,4 +146,13 @@ func main() {
 	} else {
 		fmt.Print("No marker was detected in the signal.\n")
 	}
+	if *window > 0 {
+		custom := NewBuffer(*window, filename)
+		val = custom.Read()
+		if val > 0 {
+			fmt.Printf("First marker of %d characters detected after character %d.\n", *window, val)
+		} else {
+			fmt.Printf("No marker of %d characters was detected in the signal.\n", *window)
+		}
+	}
 }